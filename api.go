@@ -3,9 +3,11 @@ package openapi
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
 	"reflect"
-	"time"
+	"slices"
+	"sync"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"gopkg.in/yaml.v2"
@@ -37,15 +39,70 @@ func WithServer(servers ...openapi3.Server) APIOpts {
 	}
 }
 
+// StatusDefault is the status code used to document the default response of an operation,
+// which describes the responses for status codes that aren't documented individually.
+const StatusDefault = 0
+
+// WithDefaultResponse adds a response to every route of the API, unless the route documents a
+// response for the same status code. Use StatusDefault to document the OpenAPI default response.
+// Example:
+//
+//	openapi.NewAPI("users", openapi.WithDefaultResponse(openapi.StatusDefault, openapi.ModelOf[openapi.ProblemDetails]()))
+func WithDefaultResponse(status int, response Model) APIOpts {
+	return func(api *API) {
+		if api.DefaultResponses == nil {
+			api.DefaultResponses = make(map[int]Model)
+		}
+		api.DefaultResponses[status] = response
+	}
+}
+
+// WithSecurityScheme adds a security scheme to the components of the API, so that it can be
+// required by routes with HasSecurity.
+func WithSecurityScheme(name string, scheme *openapi3.SecurityScheme) APIOpts {
+	return func(api *API) {
+		if api.SecuritySchemes == nil {
+			api.SecuritySchemes = make(openapi3.SecuritySchemes)
+		}
+		api.SecuritySchemes[name] = &openapi3.SecuritySchemeRef{Value: scheme}
+	}
+}
+
+// WithInt64AsString documents int, uint, int64 and uint64 values as strings with the int64 format,
+// for APIs that encode them as strings, e.g. because JavaScript numbers can't hold them.
+func WithInt64AsString() APIOpts {
+	return func(api *API) {
+		api.Int64AsString = true
+	}
+}
+
+// WithDirectionalModels documents models that have readOnly or writeOnly properties as separate
+// components for requests and responses, named with the Input and Output suffixes, e.g.
+// UserInput and UserOutput. Input components leave out readOnly properties, and output
+// components leave out writeOnly properties, including from their required properties.
+// Components that aren't used by requests or responses are left out, except for models
+// registered with RegisterModel. Creating the spec fails if a model is already named with
+// one of the suffixes, e.g. a UserInput model alongside a User model that is split.
+func WithDirectionalModels() APIOpts {
+	return func(api *API) {
+		api.DirectionalModels = true
+	}
+}
+
 // NewAPI creates a new API from the router.
 func NewAPI(name string, opts ...APIOpts) *API {
 	api := &API{
 		Name:       name,
-		KnownTypes: defaultKnownTypes,
-		Routes:     make(map[Pattern]MethodToRoute),
+		KnownTypes: maps.Clone(defaultKnownTypes),
+		// Types that are matched by their shape, rather than their identity.
+		KnownTypeMatchers: slices.Clone(defaultKnownTypeMatchers),
+		Routes:            make(map[Pattern]MethodToRoute),
 		// map of model name to schema.
-		models:   make(map[string]*openapi3.Schema),
-		comments: make(map[string]map[string]string),
+		models:     make(map[string]*openapi3.Schema),
+		modelTypes: make(map[string]reflect.Type),
+		comments:   make(map[string]map[string]string),
+		// map of anonymous type to the index used in its name.
+		anonymousTypes: make(map[reflect.Type]int),
 	}
 	for _, o := range opts {
 		o(api)
@@ -53,12 +110,9 @@ func NewAPI(name string, opts ...APIOpts) *API {
 	return api
 }
 
-var defaultKnownTypes = map[reflect.Type]openapi3.Schema{
-	reflect.TypeOf(time.Time{}):  *openapi3.NewDateTimeSchema(),
-	reflect.TypeOf(&time.Time{}): *openapi3.NewDateTimeSchema().WithNullable(),
-}
-
-// Route models a single API route.
+// Route models a single API route. Its fields must be changed with its methods, e.g.
+// HasQueryParameter, once the spec has been created, since the cached spec isn't invalidated
+// when they're changed directly.
 type Route struct {
 	// Method is the HTTP method of the route, e.g. http.MethodGet
 	Method Method
@@ -78,6 +132,11 @@ type Route struct {
 	Summary string
 	// Deprecated sets whether the route is deprecated.
 	Deprecated bool
+	// Security requirements of the route, any one of which must be met.
+	Security openapi3.SecurityRequirements
+
+	// api that the route belongs to, which is notified of changes.
+	api *API
 }
 
 // Params is a route parameter.
@@ -90,6 +149,8 @@ type Params struct {
 	Query map[string]QueryParam
 	// Header parameters are used in HTTP headers
 	Header map[string]HeaderParam
+	// Cookie parameters are sent in the Cookie header, e.g. a cookie named "session".
+	Cookie map[string]CookieParam
 }
 
 // PathParam is a paramater that's used in the path of a URL.
@@ -101,8 +162,17 @@ type PathParam struct {
 	Regexp string
 	// Type of the param (string, number, integer, boolean).
 	Type PrimitiveType
+	// Model of the param. If set, the schema is created from the Go type, and Type is ignored.
+	Model Model
+	// Style of the param, which is simple, label or matrix. Defaults to simple.
+	Style string
+	// Explode sets whether array and object values are split into separate values, e.g.
+	// .a.b rather than .a,b in the label style. Defaults to false.
+	Explode *bool
 	// ApplyCustomSchema customises the OpenAPI schema for the path parameter.
 	ApplyCustomSchema func(s *openapi3.Parameter)
+	// field the param was created from, used to document the param with the field's comment.
+	field *paramField
 }
 
 // QueryParam is a paramater that's used in the querystring of a URL.
@@ -118,20 +188,63 @@ type QueryParam struct {
 	AllowEmpty bool
 	// Type of the param (string, number, integer, boolean).
 	Type PrimitiveType
+	// Model of the param. If set, the schema is created from the Go type, and Type is ignored.
+	// Slices are array parameters, and structs and maps are object parameters.
+	Model Model
+	// Style of the param, which is form, spaceDelimited, pipeDelimited or deepObject.
+	// Defaults to form.
+	Style string
+	// Explode sets whether array and object values are split into separate values, e.g.
+	// ?tags=a&tags=b rather than ?tags=a,b. Defaults to true for the form and deepObject styles.
+	Explode *bool
+	// AllowReserved sets whether the value can contain the reserved characters of RFC 3986,
+	// e.g. / and ?, without percent-encoding.
+	AllowReserved bool
 	// ApplyCustomSchema customises the OpenAPI schema for the query parameter.
 	ApplyCustomSchema func(s *openapi3.Parameter)
+	field             *paramField
 }
 
 // HeaderParam is a parameter that's used in HTTP headers
 type HeaderParam struct {
 	// Description of the param
 	Description string
+	// Regexp is a regular expression used to validate the param.
+	// An empty string means that no validation is applied.
+	Regexp string
 	// Required sets whether the header must be present
 	Required bool
 	// Type of the param (string, number, integer, boolean)
 	Type PrimitiveType
+	// Model of the param. If set, the schema is created from the Go type, and Type is ignored.
+	Model Model
+	// Explode sets whether the properties of object values are sent as key=value pairs, e.g.
+	// role=admin,name=alex rather than role,admin,name,alex. Defaults to false.
+	Explode *bool
 	// ApplyCustomSchema customises the OpenAPI schema for the header parameter
 	ApplyCustomSchema func(s *openapi3.Parameter)
+	field             *paramField
+}
+
+// CookieParam is a parameter that's sent in the Cookie header.
+type CookieParam struct {
+	// Description of the param.
+	Description string
+	// Regexp is a regular expression used to validate the param.
+	// An empty string means that no validation is applied.
+	Regexp string
+	// Required sets whether the cookie must be present.
+	Required bool
+	// Type of the param (string, number, integer, boolean).
+	Type PrimitiveType
+	// Model of the param. If set, the schema is created from the Go type, and Type is ignored.
+	Model Model
+	// Explode sets whether array and object values are split into separate values.
+	// Defaults to true.
+	Explode *bool
+	// ApplyCustomSchema customises the OpenAPI schema for the cookie parameter.
+	ApplyCustomSchema func(s *openapi3.Parameter)
+	field             *paramField
 }
 
 type PrimitiveType string
@@ -154,6 +267,16 @@ type Pattern string
 
 // API is a model of a REST API's routes, along with their
 // request and response types.
+//
+// The exported fields, e.g. Routes, KnownTypes, StripPkgPaths, DefaultResponses and
+// ApplyCustomSchemaToType, must be set before the spec is first created, since the cached spec
+// isn't invalidated when they're changed directly. Use the methods and options, e.g. Route and
+// RegisterKnownType, to change the API afterwards.
+//
+// Functions that customise schemas, i.e. ApplyCustomSchemaToType, the ApplyCustomSchema
+// functions and methods, and OpenAPISchema, are called while the API is locked, so they must
+// not call the methods of the API, e.g. RegisterModel, which would deadlock. OpenAPISchema
+// registers other models with its Registry instead.
 type API struct {
 	// Name of the API.
 	Name string
@@ -161,6 +284,15 @@ type API struct {
 	Info openapi3.Info
 	// Servers of the API.
 	Servers []openapi3.Server
+	// SecuritySchemes that can be required by routes, by name.
+	SecuritySchemes openapi3.SecuritySchemes
+	// DefaultResponses are added to every route, unless the route documents a response for
+	// the same status code, by status code.
+	DefaultResponses map[int]Model
+	// Int64AsString documents int, uint, int64 and uint64 values as strings, see WithInt64AsString.
+	Int64AsString bool
+	// DirectionalModels documents separate request and response components, see WithDirectionalModels.
+	DirectionalModels bool
 	// Routes of the API.
 	// From patterns, to methods, to route.
 	Routes map[Pattern]MethodToRoute
@@ -178,11 +310,17 @@ type API struct {
 	// It's possible to customise the models prior to generation of the OpenAPI specification
 	// by editing this value.
 	models map[string]*openapi3.Schema
+	// modelTypes are the Go types of the models.
+	modelTypes map[string]reflect.Type
 
 	// KnownTypes are added to the OpenAPI specification output.
 	// The default implementation:
 	//   Maps time.Time to a string.
+	//   Maps other types of the standard library to their schemas, see RegisterKnownType.
 	KnownTypes map[reflect.Type]openapi3.Schema
+	// KnownTypeMatchers return the schemas of types that aren't in KnownTypes, e.g. by the
+	// interfaces they implement. The first match is used.
+	KnownTypeMatchers []KnownTypeMatcher
 
 	// comments from the package. This can be cleared once the spec has been created.
 	comments map[string]map[string]string
@@ -190,7 +328,120 @@ type API struct {
 	// ApplyCustomSchemaToType callback to customise the OpenAPI specification for a given type.
 	// Apply customisation to a specific type by checking the t parameter.
 	// Apply customisations to all types by ignoring the t parameter.
+	// It's called while the API is locked, so it must not call the methods of the API.
 	ApplyCustomSchemaToType func(t reflect.Type, s *openapi3.Schema)
+
+	// mu guards the routes, models and comments, so that the spec can be served while
+	// routes are being registered.
+	mu sync.Mutex
+	// version is incremented whenever the routes or models change.
+	version uint64
+	// cached is the result of the last call to Spec, for the current version.
+	cached *cachedSpec
+	// anonymousTypes are the indexes used to name anonymous types, in the order they were registered.
+	anonymousTypes map[reflect.Type]int
+	// fieldSchemas are the customisations of the fields of struct types, added with Customize.
+	fieldSchemas map[reflect.Type][]*FieldSchema
+	// registrations are the models registered with RegisterModel, so that they can be registered
+	// again when the models are rebuilt.
+	registrations []registration
+	// rebuildModels is set when the schemas of models change, e.g. with Customize, so that the
+	// models that have already been created are created again by the next call to Spec.
+	rebuildModels bool
+
+	// validator of requests to handlers registered with Handle, or the error that prevented
+	// it from being created, for the version of the routes.
+	validator        *RequestValidator
+	validatorErr     error
+	validatorVersion uint64
+	validatorMu      sync.Mutex
+}
+
+type cachedSpec struct {
+	spec *openapi3.T
+	err  error
+}
+
+type registration struct {
+	model Model
+	opts  []ModelOpts
+}
+
+// invalidate discards the cached spec, so that it's created again with the latest changes.
+// The caller must hold api.mu.
+func (api *API) invalidate() {
+	api.version++
+	api.cached = nil
+}
+
+// currentVersion returns the version of the routes and models.
+func (api *API) currentVersion() uint64 {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return api.version
+}
+
+// update applies the change to the route while holding the lock of the API, and discards the
+// cached spec, so that it includes the change.
+func (rm *Route) update(f func()) *Route {
+	if rm.api == nil {
+		f()
+		return rm
+	}
+	rm.api.mu.Lock()
+	defer rm.api.mu.Unlock()
+	f()
+	rm.api.invalidate()
+	return rm
+}
+
+// SortedRoutes returns copies of the routes of the API, sorted by pattern and method. The copies
+// can be read while routes are being registered, e.g. by client generators.
+func (api *API) SortedRoutes() []*Route {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	var op []*Route
+	for _, pattern := range getSortedKeys(api.Routes) {
+		for _, method := range getSortedKeys(api.Routes[pattern]) {
+			op = append(op, api.Routes[pattern][method].clone())
+		}
+	}
+	return op
+}
+
+// clone returns a copy of the route that doesn't share its maps with the route.
+func (rm *Route) clone() *Route {
+	c := *rm
+	c.Params = Params{
+		Path:   maps.Clone(rm.Params.Path),
+		Query:  maps.Clone(rm.Params.Query),
+		Header: maps.Clone(rm.Params.Header),
+		Cookie: maps.Clone(rm.Params.Cookie),
+	}
+	c.Models.Responses = maps.Clone(rm.Models.Responses)
+	c.Models.ResponseHeaders = cloneNestedMap(rm.Models.ResponseHeaders)
+	c.Models.ResponseExamples = cloneNestedMap(rm.Models.ResponseExamples)
+	c.Models.RequestContent = maps.Clone(rm.Models.RequestContent)
+	c.Models.ResponseContent = cloneNestedMap(rm.Models.ResponseContent)
+	c.Models.RequestEncoding = cloneNestedMap(rm.Models.RequestEncoding)
+	c.Models.ResponseEvents = maps.Clone(rm.Models.ResponseEvents)
+	for status, events := range c.Models.ResponseEvents {
+		c.Models.ResponseEvents[status] = slices.Clone(events)
+	}
+	c.Tags = slices.Clone(rm.Tags)
+	c.Security = slices.Clone(rm.Security)
+	return &c
+}
+
+func cloneNestedMap[K, K2 comparable, V any](m map[K]map[K2]V) map[K]map[K2]V {
+	if m == nil {
+		return nil
+	}
+	op := make(map[K]map[K2]V, len(m))
+	for k, v := range m {
+		op[k] = maps.Clone(v)
+	}
+	return op
 }
 
 // Merge route data into the existing configuration.
@@ -198,10 +449,14 @@ type API struct {
 // to take information that the router already knows and add it
 // to the specification.
 func (api *API) Merge(r Route) {
-	toUpdate := api.Route(string(r.Method), string(r.Pattern))
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.invalidate()
+	toUpdate := api.route(string(r.Method), string(r.Pattern))
 	mergeMap(toUpdate.Params.Path, r.Params.Path)
 	mergeMap(toUpdate.Params.Query, r.Params.Query)
 	mergeMap(toUpdate.Params.Header, r.Params.Header)
+	mergeMap(toUpdate.Params.Cookie, r.Params.Cookie)
 	if toUpdate.Models.Request.Type == nil {
 		toUpdate.Models.Request = r.Models.Request
 	}
@@ -218,12 +473,20 @@ func mergeMap[TKey comparable, TValue any](into, from map[TKey]TValue) {
 }
 
 // Spec creates an OpenAPI 3.0 specification document for the API.
+//
+// The specification is cached until a route is added or changed, or a model is registered,
+// so the result is shared between callers, and must not be modified. Spec is safe to call
+// while routes are being registered by other goroutines.
 func (api *API) Spec() (spec *openapi3.T, err error) {
-	spec, err = api.createOpenAPI()
-	if err != nil {
-		return
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if api.cached == nil {
+		if err = api.resetModels(); err == nil {
+			spec, err = api.createOpenAPI()
+		}
+		api.cached = &cachedSpec{spec: spec, err: err}
 	}
-	return
+	return api.cached.spec, api.cached.err
 }
 
 // Json creates a JSON representation of the OpenAPI specification.
@@ -258,6 +521,13 @@ func (api *API) Yaml() ([]byte, error) {
 
 // Route upserts a route to the API definition.
 func (api *API) Route(method, pattern string) (r *Route) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.invalidate()
+	return api.route(method, pattern)
+}
+
+func (api *API) route(method, pattern string) (r *Route) {
 	methodToRoute, ok := api.Routes[Pattern(pattern)]
 	if !ok {
 		methodToRoute = make(MethodToRoute)
@@ -268,6 +538,7 @@ func (api *API) Route(method, pattern string) (r *Route) {
 		route = &Route{
 			Method:  Method(method),
 			Pattern: Pattern(pattern),
+			api:     api,
 			Models: Models{
 				Responses: make(map[int]Model),
 			},
@@ -275,6 +546,7 @@ func (api *API) Route(method, pattern string) (r *Route) {
 				Path:   make(map[string]PathParam),
 				Query:  make(map[string]QueryParam),
 				Header: make(map[string]HeaderParam),
+				Cookie: make(map[string]CookieParam),
 			},
 		}
 		methodToRoute[Method(method)] = route
@@ -327,13 +599,15 @@ func (api *API) Trace(pattern string) (r *Route) {
 	return api.Route(http.MethodTrace, pattern)
 }
 
-// HasResponseModel configures a response for the route.
+// HasResponseModel configures a response for the route. Use StatusDefault to document the
+// OpenAPI default response.
 // Example:
 //
 //	api.Get("/user").HasResponseModel(http.StatusOK, openapi.ModelOf[User]())
 func (rm *Route) HasResponseModel(status int, response Model) *Route {
-	rm.Models.Responses[status] = response
-	return rm
+	return rm.update(func() {
+		rm.Models.Responses[status] = response
+	})
 }
 
 // HasResponseModel configures the request model of the route.
@@ -341,87 +615,196 @@ func (rm *Route) HasResponseModel(status int, response Model) *Route {
 //
 //	api.Post("/user").HasRequestModel(http.StatusOK, openapi.ModelOf[User]())
 func (rm *Route) HasRequestModel(request Model) *Route {
-	rm.Models.Request = request
-	return rm
+	return rm.update(func() {
+		rm.Models.Request = request
+	})
 }
 
 // HasPathParameter configures a path parameter for the route.
 func (rm *Route) HasPathParameter(name string, p PathParam) *Route {
-	rm.Params.Path[name] = p
-	return rm
+	return rm.update(func() {
+		rm.Params.Path[name] = p
+	})
 }
 
 // HasQueryParameter configures a query parameter for the route.
 func (rm *Route) HasQueryParameter(name string, q QueryParam) *Route {
-	rm.Params.Query[name] = q
-	return rm
+	return rm.update(func() {
+		rm.Params.Query[name] = q
+	})
 }
 
 // HasHeaderParameter configures a header parameter for the route
 func (rm *Route) HasHeaderParameter(name string, h HeaderParam) *Route {
-	rm.Params.Header[name] = h
-	return rm
+	return rm.update(func() {
+		rm.Params.Header[name] = h
+	})
+}
+
+// HasCookieParameter configures a cookie parameter for the route.
+func (rm *Route) HasCookieParameter(name string, c CookieParam) *Route {
+	return rm.update(func() {
+		rm.Params.Cookie[name] = c
+	})
 }
 
 // HasTags sets the tags for the route.
 func (rm *Route) HasTags(tags []string) *Route {
-	rm.Tags = append(rm.Tags, tags...)
-	return rm
+	return rm.update(func() {
+		rm.Tags = append(rm.Tags, tags...)
+	})
 }
 
 // HasOperationID sets the OperationID for the route.
 func (rm *Route) HasOperationID(operationID string) *Route {
-	rm.OperationID = operationID
-	return rm
+	return rm.update(func() {
+		rm.OperationID = operationID
+	})
 }
 
 // HasDescription sets the description for the route.
 func (rm *Route) HasDescription(description string) *Route {
-	rm.Description = description
-	return rm
+	return rm.update(func() {
+		rm.Description = description
+	})
 }
 
 // HasSummary sets the summary for the route.
 func (rm *Route) HasSummary(summary string) *Route {
-	rm.Summary = summary
-	return rm
+	return rm.update(func() {
+		rm.Summary = summary
+	})
 }
 
 // HasDeprecated sets the deprecated for the route.
 func (rm *Route) HasDeprecated(deprecated bool) *Route {
-	rm.Deprecated = deprecated
-	return rm
+	return rm.update(func() {
+		rm.Deprecated = deprecated
+	})
+}
+
+// HasRequestContent adds a request model for the media type, e.g. application/merge-patch+json.
+// Models of XML media types, such as application/xml, are documented using their xml struct tags,
+// and models of multipart/form-data and application/x-www-form-urlencoded use their form tags.
+// Example:
+//
+//	api.Patch("/user").HasRequestContent("application/merge-patch+json", openapi.ModelOf[UserPatch]())
+func (rm *Route) HasRequestContent(mediaType string, request Model) *Route {
+	return rm.update(func() {
+		if rm.Models.RequestContent == nil {
+			rm.Models.RequestContent = make(map[string]Model)
+		}
+		rm.Models.RequestContent[mediaType] = request
+	})
+}
+
+// HasRequestEncoding describes a part of a form request, e.g. the content types of an uploaded
+// file, or its headers.
+// Example:
+//
+//	api.Post("/avatar").
+//		HasRequestContent(openapi.ContentTypeMultipartForm, openapi.ModelOf[AvatarUpload]()).
+//		HasRequestEncoding(openapi.ContentTypeMultipartForm, "image", &openapi3.Encoding{ContentType: "image/png, image/jpeg"})
+func (rm *Route) HasRequestEncoding(mediaType string, property string, encoding *openapi3.Encoding) *Route {
+	return rm.update(func() {
+		if rm.Models.RequestEncoding == nil {
+			rm.Models.RequestEncoding = make(map[string]map[string]*openapi3.Encoding)
+		}
+		if rm.Models.RequestEncoding[mediaType] == nil {
+			rm.Models.RequestEncoding[mediaType] = make(map[string]*openapi3.Encoding)
+		}
+		rm.Models.RequestEncoding[mediaType][property] = encoding
+	})
+}
+
+// HasResponseContent adds a response model for the status code and media type, alongside
+// any JSON response model. Models of XML media types, such as application/xml, are documented
+// using their xml struct tags. Use NoSchema for content without a schema.
+// Example:
+//
+//	api.Get("/user").
+//		HasResponseModel(http.StatusOK, openapi.ModelOf[User]()).
+//		HasResponseContent(http.StatusOK, "application/xml", openapi.ModelOf[User]())
+func (rm *Route) HasResponseContent(status int, mediaType string, response Model) *Route {
+	return rm.update(func() {
+		if rm.Models.ResponseContent == nil {
+			rm.Models.ResponseContent = make(map[int]map[string]Model)
+		}
+		if rm.Models.ResponseContent[status] == nil {
+			rm.Models.ResponseContent[status] = make(map[string]Model)
+		}
+		rm.Models.ResponseContent[status][mediaType] = response
+	})
+}
+
+// HasSecurity adds a security requirement to the route. The route can be called when any one
+// of its requirements is met. The schemes must be added to the API with WithSecurityScheme.
+// Example:
+//
+//	api.Get("/user").HasSecurity(openapi3.SecurityRequirement{"bearerAuth": []string{}})
+func (rm *Route) HasSecurity(requirement openapi3.SecurityRequirement) *Route {
+	return rm.update(func() {
+		rm.Security = append(rm.Security, requirement)
+	})
 }
 
 // HasResponseHeader 为指定状态码配置响应头
 func (rm *Route) HasResponseHeader(status int, name string, h HeaderParam) *Route {
-	if rm.Models.ResponseHeaders == nil {
-		rm.Models.ResponseHeaders = make(map[int]map[string]HeaderParam)
-	}
-	if rm.Models.ResponseHeaders[status] == nil {
-		rm.Models.ResponseHeaders[status] = make(map[string]HeaderParam)
-	}
-	rm.Models.ResponseHeaders[status][name] = h
-	return rm
+	return rm.update(func() {
+		if rm.Models.ResponseHeaders == nil {
+			rm.Models.ResponseHeaders = make(map[int]map[string]HeaderParam)
+		}
+		if rm.Models.ResponseHeaders[status] == nil {
+			rm.Models.ResponseHeaders[status] = make(map[string]HeaderParam)
+		}
+		rm.Models.ResponseHeaders[status][name] = h
+	})
+}
+
+// HasResponseExample adds a named example of the response body for the status code.
+// Example:
+//
+//	api.Get("/user").HasResponseExample(http.StatusOK, "admin", User{Name: "admin"})
+func (rm *Route) HasResponseExample(status int, name string, value any) *Route {
+	return rm.update(func() {
+		if rm.Models.ResponseExamples == nil {
+			rm.Models.ResponseExamples = make(map[int]map[string]any)
+		}
+		if rm.Models.ResponseExamples[status] == nil {
+			rm.Models.ResponseExamples[status] = make(map[string]any)
+		}
+		rm.Models.ResponseExamples[status][name] = value
+	})
 }
 
 // Models defines the models used by a route.
 type Models struct {
-	Request         Model
-	Responses       map[int]Model
-	ResponseHeaders map[int]map[string]HeaderParam
+	Request          Model
+	Responses        map[int]Model
+	ResponseHeaders  map[int]map[string]HeaderParam
+	ResponseExamples map[int]map[string]any
+	// RequestContent are the request models of media types other than JSON, by media type.
+	RequestContent map[string]Model
+	// ResponseContent are the response models of media types other than JSON, by status code
+	// and media type.
+	ResponseContent map[int]map[string]Model
+	// RequestEncoding describes the parts of form requests, by media type and property name.
+	RequestEncoding map[string]map[string]*openapi3.Encoding
+	// ResponseEvents are the events of text/event-stream responses, by status code.
+	ResponseEvents map[int][]Event
+}
+
+// NoSchema is used as the model of content that has no schema, e.g. a CSV file.
+// Example:
+//
+//	api.Get("/users/export").HasResponseContent(http.StatusOK, "text/csv", openapi.NoSchema())
+func NoSchema() Model {
+	return Model{}
 }
 
 // ModelOf creates a model of type T.
 func ModelOf[T any]() Model {
-	var t T
-	m := Model{
-		Type: reflect.TypeFor[T](),
-	}
-	if sm, ok := any(t).(CustomSchemaApplier); ok {
-		m.s = sm.ApplyCustomSchema
-	}
-	return m
+	return modelFromType(reflect.TypeFor[T]())
 }
 
 // ModelFromType creates a model of type t.
@@ -433,13 +816,15 @@ func modelFromType(t reflect.Type) Model {
 	m := Model{
 		Type: t,
 	}
-	if sm, ok := reflect.New(t).Interface().(CustomSchemaApplier); ok {
+	if sm, ok := implementationOf[CustomSchemaApplier](t); ok {
 		m.s = sm.ApplyCustomSchema
 	}
 	return m
 }
 
-// CustomSchemaApplier is a type that customises its OpenAPI schema.
+// CustomSchemaApplier is a type that customises its OpenAPI schema. The method can have a value
+// or a pointer receiver. It's called while the API is locked, so it must not call the methods
+// of the API.
 type CustomSchemaApplier interface {
 	ApplyCustomSchema(s *openapi3.Schema)
 }
@@ -0,0 +1,166 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSpecIsCached(t *testing.T) {
+	api := NewAPI("cache")
+	api.Get("/users").HasResponseModel(http.StatusOK, ModelOf[[]User]())
+
+	first, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	second, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	if first != second {
+		t.Error("expected the spec to be cached")
+	}
+
+	api.Get("/users/{id}").HasResponseModel(http.StatusOK, ModelOf[User]())
+	third, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	if third == second {
+		t.Fatal("expected adding a route to invalidate the cached spec")
+	}
+	if third.Paths.Find("/users/{id}") == nil {
+		t.Error("expected the new route to be documented")
+	}
+
+	api.Get("/users/{id}").HasDescription("Get a user.")
+	fourth, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	if actual := fourth.Paths.Find("/users/{id}").Get.Description; actual != "Get a user." {
+		t.Errorf("expected changes to a route to invalidate the cached spec, got description %q", actual)
+	}
+}
+
+func TestSpecRebuildsCustomisedModels(t *testing.T) {
+	api := NewAPI("users")
+	api.Get("/users").HasResponseModel(http.StatusOK, ModelOf[CustomizeUser]())
+	userName, _, err := api.RegisterModel(ModelOf[User]())
+	if err != nil {
+		t.Fatalf("failed to register model: %v", err)
+	}
+	if _, err = api.Spec(); err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+
+	Customize(api, func(f *Fields[CustomizeUser], u *CustomizeUser) {
+		f.Of(&u.Email).Format("email")
+	})
+	RegisterKnownType[int](api, *openapi3.NewStringSchema())
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	var user *openapi3.Schema
+	for _, ref := range spec.Components.Schemas {
+		if ref.Value.Properties["emailAddress"] != nil {
+			user = ref.Value
+		}
+	}
+	if user == nil {
+		t.Fatal("expected the customised model to be documented")
+	}
+	if actual := user.Properties["emailAddress"].Value.Format; actual != "email" {
+		t.Errorf("expected Customize to change the format of a model in the spec, got %q", actual)
+	}
+	if actual := user.Properties["age"].Value.Type; !actual.Is(openapi3.TypeString) {
+		t.Errorf("expected RegisterKnownType to change the type of a field in the spec, got %v", actual)
+	}
+	if spec.Components.Schemas[userName] == nil {
+		t.Error("expected the registered model to be kept")
+	}
+}
+
+func TestSpecNamesAnonymousTypesConsistently(t *testing.T) {
+	create := func() []byte {
+		api := NewAPI("anonymous")
+		api.Post("/a").
+			HasRequestModel(ModelOf[struct{ A string }]()).
+			HasResponseModel(http.StatusOK, ModelOf[struct{ B string }]())
+		api.Post("/b").
+			HasRequestModel(ModelOf[struct{ C string }]()).
+			HasResponseModel(http.StatusCreated, ModelOf[struct{ D string }]()).
+			HasResponseModel(http.StatusOK, ModelOf[struct{ E string }]())
+		data, err := api.Json()
+		if err != nil {
+			t.Fatalf("failed to create spec: %v", err)
+		}
+		return data
+	}
+	expected := create()
+	for i := 0; i < 10; i++ {
+		if diff := cmp.Diff(string(expected), string(create())); diff != "" {
+			t.Fatal(diff)
+		}
+	}
+}
+
+func TestSpecConcurrentWithRegistration(t *testing.T) {
+	api := NewAPI("concurrent")
+	api.Get("/health").HasResponseModel(http.StatusOK, ModelOf[string]())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			api.Get(fmt.Sprintf("/users%d/{id}", i)).
+				HasDescription("Get a user.").
+				HasQueryParameter("fields", QueryParam{}).
+				HasResponseModel(http.StatusOK, ModelOf[User]())
+			api.RegisterModel(ModelOf[UserResponse]())
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := api.Spec(); err != nil {
+				t.Errorf("failed to create spec: %v", err)
+			}
+			api.RegisteredModels()
+		}()
+	}
+	wg.Wait()
+
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	if actual := spec.Paths.Len(); actual != 9 {
+		t.Errorf("expected 9 paths, got %d", actual)
+	}
+}
+
+func TestSortedRoutesAreCopies(t *testing.T) {
+	api := NewAPI("users")
+	api.Post("/users").HasQueryParameter("dryRun", QueryOf[bool](""))
+	api.Get("/users").HasResponseModel(http.StatusOK, ModelOf[[]User]())
+
+	routes := api.SortedRoutes()
+	var actual []string
+	for _, r := range routes {
+		actual = append(actual, fmt.Sprintf("%s %s", r.Method, r.Pattern))
+	}
+	if diff := cmp.Diff([]string{"GET /users", "POST /users"}, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	api.Post("/users").HasQueryParameter("notify", QueryOf[bool](""))
+	if len(routes[1].Params.Query) != 1 {
+		t.Errorf("expected the copy not to change when the route changes, got %v", routes[1].Params.Query)
+	}
+}
@@ -0,0 +1,269 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const componentSchemaPrefix = "#/components/schemas/"
+
+// splitDirectionalModels replaces the components that have readOnly or writeOnly properties,
+// and the components that reference them, with separate components for requests and
+// responses, and references them from the requests and responses of the operations. Both
+// components of the registered models are kept, even if they aren't used by the paths.
+// Components aren't split if the name of the request or response component is already used.
+func splitDirectionalModels(spec *openapi3.T, registered map[string]bool) (errs SpecErrors) {
+	schemas := spec.Components.Schemas
+	split := make(map[string]bool)
+	for name, ref := range schemas {
+		if hasDirectionalProperties(ref.Value) {
+			split[name] = true
+		}
+	}
+	if len(split) == 0 {
+		return nil
+	}
+	// Components that reference a split component are split too, so that they can reference
+	// the request or response component.
+	for changed := true; changed; {
+		changed = false
+		for name, ref := range schemas {
+			if !split[name] && referencesAny(ref.Value, split) {
+				split[name] = true
+				changed = true
+			}
+		}
+	}
+
+	for name := range split {
+		for _, variant := range []string{name + "Input", name + "Output"} {
+			if schemas[variant] != nil {
+				errs = append(errs, &SpecError{Err: fmt.Errorf("the request and response components of %s can't be created, because the name %s is already used by another component: rename one of the models", name, variant)})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	for name := range split {
+		s := schemas[name].Value
+		schemas[name+"Input"] = openapi3.NewSchemaRef("", directionalSchema(s, true, split))
+		schemas[name+"Output"] = openapi3.NewSchemaRef("", directionalSchema(s, false, split))
+		delete(schemas, name)
+	}
+
+	mapPathSchemaRefs(spec.Paths, func(ref *openapi3.SchemaRef, input bool) *openapi3.SchemaRef {
+		return directionalRef(ref, input, split)
+	})
+
+	// Remove the request and response components that aren't used by the paths. Components that
+	// aren't split can't reference them.
+	used := make(map[string]bool)
+	var use func(ref *openapi3.SchemaRef)
+	use = func(ref *openapi3.SchemaRef) {
+		name := strings.TrimPrefix(ref.Ref, componentSchemaPrefix)
+		if used[name] || schemas[name] == nil {
+			return
+		}
+		used[name] = true
+		walkSchemaRefs(schemas[name].Value, use)
+	}
+	mapPathSchemaRefs(spec.Paths, func(ref *openapi3.SchemaRef, input bool) *openapi3.SchemaRef {
+		if ref.Ref != "" {
+			use(ref)
+		} else {
+			walkSchemaRefs(ref.Value, use)
+		}
+		return ref
+	})
+	for name := range registered {
+		if split[name] {
+			use(openapi3.NewSchemaRef(componentSchemaPrefix+name+"Input", nil))
+			use(openapi3.NewSchemaRef(componentSchemaPrefix+name+"Output", nil))
+		}
+	}
+	for name := range split {
+		for _, variant := range []string{name + "Input", name + "Output"} {
+			if !used[variant] {
+				delete(schemas, variant)
+			}
+		}
+	}
+	return nil
+}
+
+// mapPathSchemaRefs replaces the schemas of the parameters, request bodies and responses of the
+// paths with the result of fn, which is told whether the schema is part of a request.
+func mapPathSchemaRefs(paths *openapi3.Paths, fn func(ref *openapi3.SchemaRef, input bool) *openapi3.SchemaRef) {
+	for _, path := range paths.Map() {
+		mapParameterSchemaRefs(path.Parameters, fn)
+		for _, op := range path.Operations() {
+			mapParameterSchemaRefs(op.Parameters, fn)
+			if op.RequestBody != nil && op.RequestBody.Value != nil {
+				mapContentSchemaRefs(op.RequestBody.Value.Content, true, fn)
+			}
+			if op.Responses == nil {
+				continue
+			}
+			for _, response := range op.Responses.Map() {
+				if response.Value == nil {
+					continue
+				}
+				mapContentSchemaRefs(response.Value.Content, false, fn)
+				for _, header := range response.Value.Headers {
+					if header.Value != nil && header.Value.Schema != nil {
+						header.Value.Schema = fn(header.Value.Schema, false)
+					}
+				}
+			}
+		}
+	}
+}
+
+func mapParameterSchemaRefs(params openapi3.Parameters, fn func(ref *openapi3.SchemaRef, input bool) *openapi3.SchemaRef) {
+	for _, param := range params {
+		if param.Value == nil {
+			continue
+		}
+		if param.Value.Schema != nil {
+			param.Value.Schema = fn(param.Value.Schema, true)
+		}
+		mapContentSchemaRefs(param.Value.Content, true, fn)
+	}
+}
+
+func mapContentSchemaRefs(content openapi3.Content, input bool, fn func(ref *openapi3.SchemaRef, input bool) *openapi3.SchemaRef) {
+	for _, mt := range content {
+		if mt.Schema != nil {
+			mt.Schema = fn(mt.Schema, input)
+		}
+		// The events of event streams have their own schemas, see eventStreamMediaType.
+		if events, ok := mt.Extensions["x-events"].(map[string]*openapi3.SchemaRef); ok {
+			for name, ref := range events {
+				events[name] = fn(ref, input)
+			}
+		}
+	}
+}
+
+// hasDirectionalProperties returns true if any of the properties of the schema are readOnly or writeOnly.
+func hasDirectionalProperties(s *openapi3.Schema) bool {
+	if s == nil {
+		return false
+	}
+	for _, p := range s.Properties {
+		if p.Value != nil && (p.Value.ReadOnly || p.Value.WriteOnly) {
+			return true
+		}
+	}
+	return false
+}
+
+// referencesAny returns true if the schema, or any of the schemas it contains, references one of the components.
+func referencesAny(s *openapi3.Schema, names map[string]bool) (found bool) {
+	walkSchemaRefs(s, func(ref *openapi3.SchemaRef) {
+		if names[strings.TrimPrefix(ref.Ref, componentSchemaPrefix)] {
+			found = true
+		}
+	})
+	return found
+}
+
+// walkSchemaRefs calls fn with each reference in the schema, including the references of
+// the schemas it contains, but not of the components it references.
+func walkSchemaRefs(s *openapi3.Schema, fn func(ref *openapi3.SchemaRef)) {
+	if s == nil {
+		return
+	}
+	visit := func(ref *openapi3.SchemaRef) {
+		if ref == nil {
+			return
+		}
+		if ref.Ref != "" {
+			fn(ref)
+			return
+		}
+		walkSchemaRefs(ref.Value, fn)
+	}
+	visit(s.Items)
+	visit(s.AdditionalProperties.Schema)
+	visit(s.Not)
+	for _, p := range s.Properties {
+		visit(p)
+	}
+	for _, refs := range []openapi3.SchemaRefs{s.AllOf, s.OneOf, s.AnyOf} {
+		for _, ref := range refs {
+			visit(ref)
+		}
+	}
+}
+
+// directionalRef returns the reference to the request or response component of a split
+// component, or a copy of the schema, which references them.
+func directionalRef(ref *openapi3.SchemaRef, input bool, split map[string]bool) *openapi3.SchemaRef {
+	if ref == nil {
+		return nil
+	}
+	if ref.Ref != "" {
+		name := strings.TrimPrefix(ref.Ref, componentSchemaPrefix)
+		if !split[name] {
+			return ref
+		}
+		return openapi3.NewSchemaRef(componentSchemaPrefix+name+directionSuffix(input), nil)
+	}
+	if !referencesAny(ref.Value, split) && !hasDirectionalProperties(ref.Value) {
+		return ref
+	}
+	return openapi3.NewSchemaRef("", directionalSchema(ref.Value, input, split))
+}
+
+func directionSuffix(input bool) string {
+	if input {
+		return "Input"
+	}
+	return "Output"
+}
+
+// directionalSchema returns a copy of the schema for requests, or for responses. Requests leave
+// out readOnly properties, and responses leave out writeOnly properties.
+func directionalSchema(s *openapi3.Schema, input bool, split map[string]bool) *openapi3.Schema {
+	c := *s
+	c.Items = directionalRef(s.Items, input, split)
+	c.AdditionalProperties.Schema = directionalRef(s.AdditionalProperties.Schema, input, split)
+	c.Not = directionalRef(s.Not, input, split)
+	c.AllOf = directionalRefs(s.AllOf, input, split)
+	c.OneOf = directionalRefs(s.OneOf, input, split)
+	c.AnyOf = directionalRefs(s.AnyOf, input, split)
+	if s.Properties == nil {
+		return &c
+	}
+	c.Properties = make(openapi3.Schemas, len(s.Properties))
+	omitted := make(map[string]bool)
+	for name, p := range s.Properties {
+		if p.Value != nil && (input && p.Value.ReadOnly || !input && p.Value.WriteOnly) {
+			omitted[name] = true
+			continue
+		}
+		c.Properties[name] = directionalRef(p, input, split)
+	}
+	c.Required = nil
+	for _, name := range s.Required {
+		if !omitted[name] {
+			c.Required = append(c.Required, name)
+		}
+	}
+	return &c
+}
+
+func directionalRefs(refs openapi3.SchemaRefs, input bool, split map[string]bool) openapi3.SchemaRefs {
+	if refs == nil {
+		return nil
+	}
+	op := make(openapi3.SchemaRefs, len(refs))
+	for i, ref := range refs {
+		op[i] = directionalRef(ref, input, split)
+	}
+	return op
+}
@@ -0,0 +1,562 @@
+// Package docs renders an OpenAPI specification, such as the one created by
+// API.Spec(), as Markdown or as a static multi-page HTML site.
+package docs
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// defaultTag is used to group operations that don't have any tags.
+const defaultTag = "default"
+
+// methodOrder is the order that operations on the same path are listed in.
+var methodOrder = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+// document is the renderer independent view of a specification.
+type document struct {
+	Title       string
+	Version     string
+	Description string
+	Servers     []server
+	Tags        []*tagGroup
+	// Operations in path order, each operation is only listed once.
+	Operations []*operation
+}
+
+type server struct {
+	URL         string
+	Description string
+}
+
+type tagGroup struct {
+	Name string
+	// File is the name of the HTML page of the tag.
+	File        string
+	Description string
+	Operations  []*operation
+}
+
+type operation struct {
+	Method      string
+	Path        string
+	Anchor      string
+	OperationID string
+	Summary     string
+	Description string
+	Deprecated  bool
+	Params      []param
+	RequestBody []content
+	Responses   []response
+}
+
+type param struct {
+	Name        string
+	In          string
+	Type        string
+	Required    bool
+	Description string
+}
+
+type response struct {
+	Status      string
+	Description string
+	Headers     []param
+	Content     []content
+}
+
+type content struct {
+	MediaType string
+	Fields    []field
+	Example   string
+	// ExampleLanguage is the language of the example, e.g. json or xml, or empty for plain text.
+	ExampleLanguage string
+}
+
+// field is a single line of an expanded schema tree.
+type field struct {
+	Depth       int
+	Name        string
+	Type        string
+	Required    bool
+	Description string
+	Enum        string
+	Deprecated  bool
+}
+
+// Indent returns the Markdown list indentation for the field.
+func (f field) Indent() string {
+	return strings.Repeat("  ", f.Depth)
+}
+
+func newDocument(spec *openapi3.T) (doc *document, err error) {
+	if spec == nil {
+		return nil, fmt.Errorf("spec is nil")
+	}
+	doc = &document{}
+	if spec.Info != nil {
+		doc.Title = spec.Info.Title
+		doc.Version = spec.Info.Version
+		doc.Description = spec.Info.Description
+	}
+	for _, s := range spec.Servers {
+		if s == nil {
+			continue
+		}
+		doc.Servers = append(doc.Servers, server{URL: s.URL, Description: s.Description})
+	}
+
+	tags := make(map[string]*tagGroup)
+	// Tags and operations whose names only differ by punctuation or case, e.g. "A B" and
+	// "a-b", are given unique slugs, so that they don't share a page or an anchor.
+	files, anchors := slugs{}, slugs{}
+	getTag := func(name string) *tagGroup {
+		if tg, ok := tags[name]; ok {
+			return tg
+		}
+		tg := &tagGroup{Name: name, File: "tag-" + files.unique(name) + ".html"}
+		if t := spec.Tags.Get(name); t != nil {
+			tg.Description = t.Description
+		}
+		tags[name] = tg
+		return tg
+	}
+
+	if spec.Paths != nil {
+		paths := spec.Paths.Map()
+		for _, path := range getSortedKeys(paths) {
+			item := paths[path]
+			ops := item.Operations()
+			for _, method := range methodOrder {
+				op, ok := ops[method]
+				if !ok {
+					continue
+				}
+				o, err := newOperation(method, path, op)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: %w", method, path, err)
+				}
+				o.Anchor = anchors.unique(method + " " + path)
+				doc.Operations = append(doc.Operations, o)
+				opTags := op.Tags
+				if len(opTags) == 0 {
+					opTags = []string{defaultTag}
+				}
+				for _, t := range opTags {
+					tg := getTag(t)
+					tg.Operations = append(tg.Operations, o)
+				}
+			}
+		}
+	}
+
+	// Tags declared in the spec keep their order, the rest are sorted by name.
+	for _, t := range spec.Tags {
+		if tg, ok := tags[t.Name]; ok {
+			doc.Tags = append(doc.Tags, tg)
+			delete(tags, t.Name)
+		}
+	}
+	for _, name := range getSortedKeys(tags) {
+		doc.Tags = append(doc.Tags, tags[name])
+	}
+	return doc, nil
+}
+
+func newOperation(method, path string, op *openapi3.Operation) (o *operation, err error) {
+	o = &operation{
+		Method:      method,
+		Path:        path,
+		OperationID: op.OperationID,
+		Summary:     op.Summary,
+		Description: op.Description,
+		Deprecated:  op.Deprecated,
+	}
+	for _, pr := range op.Parameters {
+		if pr == nil || pr.Value == nil {
+			continue
+		}
+		p := pr.Value
+		o.Params = append(o.Params, param{
+			Name:        p.Name,
+			In:          p.In,
+			Type:        schemaType(p.Schema),
+			Required:    p.Required,
+			Description: p.Description,
+		})
+	}
+	sort.SliceStable(o.Params, func(i, j int) bool {
+		return o.Params[i].In < o.Params[j].In
+	})
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		if o.RequestBody, err = newContent(op.RequestBody.Value.Content); err != nil {
+			return nil, fmt.Errorf("request body: %w", err)
+		}
+	}
+	if op.Responses != nil {
+		responses := op.Responses.Map()
+		for _, status := range getSortedKeys(responses) {
+			rr := responses[status]
+			if rr == nil || rr.Value == nil {
+				continue
+			}
+			r := response{Status: status}
+			if rr.Value.Description != nil {
+				r.Description = *rr.Value.Description
+			}
+			for _, name := range getSortedKeys(rr.Value.Headers) {
+				h := rr.Value.Headers[name]
+				if h == nil || h.Value == nil {
+					continue
+				}
+				r.Headers = append(r.Headers, param{
+					Name:        name,
+					In:          openapi3.ParameterInHeader,
+					Type:        schemaType(h.Value.Schema),
+					Required:    h.Value.Required,
+					Description: h.Value.Description,
+				})
+			}
+			if r.Content, err = newContent(rr.Value.Content); err != nil {
+				return nil, fmt.Errorf("response %s: %w", status, err)
+			}
+			// Skip the empty default response that is added to every operation.
+			if status == "default" && r.Description == "" && len(r.Content) == 0 && len(r.Headers) == 0 {
+				continue
+			}
+			o.Responses = append(o.Responses, r)
+		}
+	}
+	return o, nil
+}
+
+func newContent(c openapi3.Content) (op []content, err error) {
+	for _, mediaType := range getSortedKeys(c) {
+		mt := c[mediaType]
+		if mt == nil {
+			continue
+		}
+		ct := content{MediaType: mediaType}
+		if mt.Schema != nil {
+			ct.Fields = schemaFields(mt.Schema, 0, "", true, nil)
+		}
+		example := mt.Example
+		if example == nil && len(mt.Examples) > 0 {
+			for _, name := range getSortedKeys(mt.Examples) {
+				if e := mt.Examples[name]; e != nil && e.Value != nil {
+					example = e.Value.Value
+					break
+				}
+			}
+		}
+		if example == nil && mt.Schema != nil {
+			example = exampleValue(mt.Schema, nil)
+		}
+		// Examples of other media types are written as they are if they're strings, e.g. XML
+		// documents, otherwise they're written as JSON.
+		lang := exampleLanguage(mediaType)
+		if s, ok := example.(string); ok && lang != "json" {
+			ct.Example, ct.ExampleLanguage = s, lang
+		} else if example != nil {
+			b, err := json.MarshalIndent(example, "", "  ")
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal example for %q: %w", mediaType, err)
+			}
+			ct.Example, ct.ExampleLanguage = string(b), "json"
+		}
+		op = append(op, ct)
+	}
+	return op, nil
+}
+
+// schemaFields expands the schema into a flat list of fields, following references.
+// References that are already being expanded are not expanded again, to avoid
+// infinite recursion on recursive types.
+func schemaFields(sr *openapi3.SchemaRef, depth int, name string, required bool, seen []string) (fields []field) {
+	if sr == nil || sr.Value == nil {
+		return nil
+	}
+	s := sr.Value
+	if name != "" {
+		fields = append(fields, field{
+			Depth:       depth,
+			Name:        name,
+			Type:        schemaType(sr),
+			Required:    required,
+			Description: s.Description,
+			Enum:        enumValues(s.Enum),
+			Deprecated:  s.Deprecated,
+		})
+		depth++
+	}
+	if sr.Ref != "" {
+		if slices.Contains(seen, sr.Ref) {
+			return fields
+		}
+		seen = append(seen, sr.Ref)
+	}
+	switch {
+	case s.Type.Is(openapi3.TypeArray):
+		if s.Items != nil && s.Items.Value != nil && len(s.Items.Value.Properties) > 0 {
+			fields = append(fields, schemaFields(s.Items, depth, "[]", true, seen)...)
+		}
+	case len(s.Properties) > 0:
+		for _, k := range getSortedKeys(s.Properties) {
+			fields = append(fields, schemaFields(s.Properties[k], depth, k, slices.Contains(s.Required, k), seen)...)
+		}
+	case s.AdditionalProperties.Schema != nil:
+		ap := s.AdditionalProperties.Schema
+		if ap.Value != nil && len(ap.Value.Properties) > 0 {
+			fields = append(fields, schemaFields(ap, depth, "{key}", true, seen)...)
+		}
+	}
+	if name == "" && len(fields) == 0 {
+		// A schema with no properties, e.g. a string, is shown as a single line.
+		fields = append(fields, field{
+			Type:        schemaType(sr),
+			Required:    true,
+			Description: s.Description,
+			Enum:        enumValues(s.Enum),
+			Deprecated:  s.Deprecated,
+		})
+	}
+	return fields
+}
+
+// schemaType returns a short, human readable type of the schema,
+// e.g. "array of Topic" or "string (date-time)".
+func schemaType(sr *openapi3.SchemaRef) string {
+	if sr == nil {
+		return ""
+	}
+	if sr.Ref != "" {
+		return refName(sr.Ref)
+	}
+	if sr.Value == nil {
+		return ""
+	}
+	s := sr.Value
+	var t string
+	switch {
+	case s.Type.Is(openapi3.TypeArray):
+		t = "array of " + schemaType(s.Items)
+	case s.Type.Is(openapi3.TypeObject) && s.AdditionalProperties.Schema != nil:
+		t = "map of " + schemaType(s.AdditionalProperties.Schema)
+	case s.Type != nil && len(*s.Type) > 0:
+		t = strings.Join(s.Type.Slice(), " | ")
+	case len(s.OneOf) > 0:
+		t = joinTypes(s.OneOf, " | ")
+	case len(s.AnyOf) > 0:
+		t = joinTypes(s.AnyOf, " | ")
+	case len(s.AllOf) > 0:
+		t = joinTypes(s.AllOf, " & ")
+	default:
+		t = "any"
+	}
+	if s.Format != "" {
+		t += " (" + s.Format + ")"
+	}
+	if s.Nullable {
+		t += ", nullable"
+	}
+	return t
+}
+
+func joinTypes(refs openapi3.SchemaRefs, sep string) string {
+	types := make([]string, len(refs))
+	for i, r := range refs {
+		types[i] = schemaType(r)
+	}
+	return strings.Join(types, sep)
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+func enumValues(enum []any) string {
+	values := make([]string, len(enum))
+	for i, v := range enum {
+		values[i] = fmt.Sprint(v)
+	}
+	return strings.Join(values, ", ")
+}
+
+// exampleValue creates an example payload for the schema, preferring any examples,
+// defaults or enum values from the schema itself.
+func exampleValue(sr *openapi3.SchemaRef, seen []string) any {
+	if sr == nil || sr.Value == nil {
+		return nil
+	}
+	if sr.Ref != "" {
+		if slices.Contains(seen, sr.Ref) {
+			return nil
+		}
+		seen = append(seen, sr.Ref)
+	}
+	s := sr.Value
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case s.Default != nil:
+		return s.Default
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case len(s.OneOf) > 0:
+		return exampleValue(s.OneOf[0], seen)
+	case len(s.AnyOf) > 0:
+		return exampleValue(s.AnyOf[0], seen)
+	case len(s.AllOf) > 0:
+		m := make(map[string]any)
+		for _, r := range s.AllOf {
+			if v, ok := exampleValue(r, seen).(map[string]any); ok {
+				for k, vv := range v {
+					m[k] = vv
+				}
+			}
+		}
+		return m
+	}
+	switch {
+	case s.Type.Is(openapi3.TypeString):
+		return exampleString(s)
+	case s.Type.Is(openapi3.TypeInteger):
+		if s.Min != nil {
+			return int64(*s.Min)
+		}
+		return 0
+	case s.Type.Is(openapi3.TypeNumber):
+		if s.Min != nil {
+			return *s.Min
+		}
+		return 0.0
+	case s.Type.Is(openapi3.TypeBoolean):
+		return true
+	case s.Type.Is(openapi3.TypeArray):
+		item := exampleValue(s.Items, seen)
+		if item == nil {
+			return []any{}
+		}
+		return []any{item}
+	}
+	m := make(map[string]any)
+	for name, p := range s.Properties {
+		m[name] = exampleValue(p, seen)
+	}
+	if s.AdditionalProperties.Schema != nil {
+		m["key"] = exampleValue(s.AdditionalProperties.Schema, seen)
+	}
+	return m
+}
+
+func exampleString(s *openapi3.Schema) string {
+	switch s.Format {
+	case "date-time":
+		return "2006-01-02T15:04:05Z"
+	case "date":
+		return "2006-01-02"
+	case "time":
+		return "15:04:05"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "123e4567-e89b-12d3-a456-426614174000"
+	case "uri", "url":
+		return "https://example.com"
+	case "ipv4":
+		return "192.0.2.1"
+	case "ipv6":
+		return "2001:db8::1"
+	case "byte":
+		return "U3dhZ2dlciByb2Nrcw=="
+	case "binary":
+		return "<binary>"
+	}
+	return "string"
+}
+
+// exampleLanguage returns the language of examples of the media type, e.g. xml for
+// application/atom+xml, or an empty string for plain text.
+func exampleLanguage(mediaType string) string {
+	mt, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		mt = strings.ToLower(mediaType)
+	}
+	switch {
+	case mt == "application/json" || strings.HasSuffix(mt, "+json"):
+		return "json"
+	case strings.HasSuffix(mt, "/xml") || strings.HasSuffix(mt, "+xml"):
+		return "xml"
+	case strings.HasSuffix(mt, "/yaml") || strings.HasSuffix(mt, "/x-yaml") || strings.HasSuffix(mt, "+yaml"):
+		return "yaml"
+	case mt == "text/html":
+		return "html"
+	case mt == "text/csv":
+		return "csv"
+	}
+	return ""
+}
+
+var slugInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slug(s string) string {
+	return strings.Trim(slugInvalid.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+// slugs records the slugs that have been used, to number slugs that are used again, e.g. a-b-2.
+type slugs map[string]bool
+
+func (used slugs) unique(s string) string {
+	base := slug(s)
+	// Names without ASCII letters or digits, e.g. in other scripts, are identified by their bytes.
+	if base == "" && s != "" {
+		base = fmt.Sprintf("%x", s)
+	}
+	if base == "" {
+		base = "untitled"
+	}
+	s = base
+	for i := 2; used[s]; i++ {
+		s = fmt.Sprintf("%s-%d", base, i)
+	}
+	used[s] = true
+	return s
+}
+
+func getSortedKeys[V any](m map[string]V) (op []string) {
+	for k := range m {
+		op = append(op, k)
+	}
+	sort.Slice(op, func(i, j int) bool {
+		// Sort status codes numerically, and "default" last.
+		ni, erri := strconv.Atoi(op[i])
+		nj, errj := strconv.Atoi(op[j])
+		if erri == nil && errj == nil {
+			return ni < nj
+		}
+		if erri == nil || errj == nil {
+			return erri == nil
+		}
+		return op[i] < op[j]
+	})
+	return op
+}
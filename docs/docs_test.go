@@ -0,0 +1,183 @@
+package docs
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/ihezebin/openapi"
+)
+
+type Topic struct {
+	// Namespace of the topic.
+	Namespace string `json:"namespace"`
+	Private   bool   `json:"private"`
+	Tags      []Tag  `json:"tags"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+type Error struct {
+	Message string `json:"message"`
+}
+
+func newTestAPI() *openapi.API {
+	api := openapi.NewAPI("topics")
+	api.StripPkgPaths = []string{"github.com/ihezebin/openapi"}
+	api.Get("/topic/{id}").
+		HasPathParameter("id", openapi.PathParam{
+			Description: "id of the topic",
+			Regexp:      `\d+`,
+		}).
+		HasQueryParameter("limit", openapi.QueryParam{
+			Description: "max | count",
+			Type:        openapi.PrimitiveTypeInteger,
+		}).
+		HasResponseModel(http.StatusOK, openapi.ModelOf[Topic]()).
+		HasResponseModel(http.StatusNotFound, openapi.ModelOf[Error]()).
+		HasTags([]string{"Topic"}).
+		HasOperationID("getTopic").
+		HasSummary("Get one topic by id")
+	api.Post("/topic").
+		HasRequestModel(openapi.ModelOf[Topic]()).
+		HasResponseModel(http.StatusCreated, openapi.ModelOf[Topic]()).
+		HasTags([]string{"Topic"})
+	api.Get("/health").
+		HasResponseModel(http.StatusOK, openapi.ModelOf[string]())
+	return api
+}
+
+func TestMarkdown(t *testing.T) {
+	spec, err := newTestAPI().Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	md, err := Markdown(spec)
+	if err != nil {
+		t.Fatalf("failed to render markdown: %v", err)
+	}
+	expected := []string{
+		"# topics",
+		"### Topic",
+		"| GET | [/topic/{id}](#get-topic-id) | getTopic | Get one topic by id |",
+		"### default",
+		"| id | path | string | yes | id of the topic |",
+		`| limit | query | integer | no | max \| count |`,
+		"- `namespace` (string, required): Namespace of the topic.",
+		"- `tags` (array of Tag, nullable, required)",
+		"  - `[]` (Tag, required)",
+		"    - `name` (string, required)",
+		"#### 404",
+		`"namespace": "string"`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(string(md), e) {
+			t.Errorf("expected markdown to contain %q", e)
+		}
+	}
+	if n := strings.Count(string(md), "## GET /topic/{id}\n"); n != 1 {
+		t.Errorf("expected operation to be rendered once, got %d", n)
+	}
+	if t.Failed() {
+		t.Log("\n" + string(md))
+	}
+}
+
+func TestHTML(t *testing.T) {
+	spec, err := newTestAPI().Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	pages, err := HTML(spec)
+	if err != nil {
+		t.Fatalf("failed to render html: %v", err)
+	}
+	for _, name := range []string{IndexPage, "tag-topic.html", "tag-default.html"} {
+		if _, ok := pages[name]; !ok {
+			t.Errorf("expected page %q", name)
+		}
+	}
+	if !strings.Contains(string(pages[IndexPage]), `<a href="tag-topic.html#get-topic-id">/topic/{id}</a>`) {
+		t.Errorf("expected index to link to the operation\n%s", pages[IndexPage])
+	}
+	if !strings.Contains(string(pages["tag-topic.html"]), `<section id="post-topic">`) {
+		t.Errorf("expected tag page to contain the operation\n%s", pages["tag-topic.html"])
+	}
+}
+
+func TestHTMLUniqueSlugs(t *testing.T) {
+	api := openapi.NewAPI("slugs")
+	api.Get("/a-b").HasResponseModel(http.StatusOK, openapi.ModelOf[string]()).HasTags([]string{"A B"})
+	api.Get("/a.b").HasResponseModel(http.StatusOK, openapi.ModelOf[string]()).HasTags([]string{"a-b"})
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	pages, err := HTML(spec)
+	if err != nil {
+		t.Fatalf("failed to render html: %v", err)
+	}
+	for _, expected := range []string{
+		`<a href="tag-a-b.html#get-a-b">/a-b</a>`,
+		`<a href="tag-a-b-2.html#get-a-b-2">/a.b</a>`,
+	} {
+		if !strings.Contains(string(pages[IndexPage]), expected) {
+			t.Errorf("expected index to contain %s\n%s", expected, pages[IndexPage])
+		}
+	}
+}
+
+func TestExampleLanguage(t *testing.T) {
+	spec, err := newTestAPI().Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	content := spec.Paths.Find("/health").Get.Responses.Status(http.StatusOK).Value.Content
+	content["application/xml"] = &openapi3.MediaType{Example: "<health>ok</health>"}
+	content["text/plain"] = &openapi3.MediaType{Example: "ok"}
+	md, err := Markdown(spec)
+	if err != nil {
+		t.Fatalf("failed to render markdown: %v", err)
+	}
+	for _, expected := range []string{
+		"```json\n\"string\"\n```",
+		"```xml\n<health>ok</health>\n```",
+		"```\nok\n```",
+	} {
+		if !strings.Contains(string(md), expected) {
+			t.Errorf("expected markdown to contain %q\n%s", expected, md)
+		}
+	}
+	pages, err := HTML(spec)
+	if err != nil {
+		t.Fatalf("failed to render html: %v", err)
+	}
+	if expected := `<pre><code class="language-xml">&lt;health&gt;ok&lt;/health&gt;</code></pre>`; !strings.Contains(string(pages["tag-default.html"]), expected) {
+		t.Errorf("expected html to contain %s\n%s", expected, pages["tag-default.html"])
+	}
+}
+
+func TestHTMLEmptySlugs(t *testing.T) {
+	api := openapi.NewAPI("slugs")
+	api.Get("/a").HasResponseModel(http.StatusOK, openapi.ModelOf[string]()).HasTags([]string{"話題"})
+	api.Get("/b").HasResponseModel(http.StatusOK, openapi.ModelOf[string]()).HasTags([]string{"!!"})
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	pages, err := HTML(spec)
+	if err != nil {
+		t.Fatalf("failed to render html: %v", err)
+	}
+	if _, ok := pages["tag-.html"]; ok {
+		t.Error("expected tags without letters or digits not to have an empty slug")
+	}
+	for _, expected := range []string{"tag-e8a9b1e9a18c.html", "tag-2121.html"} {
+		if _, ok := pages[expected]; !ok {
+			t.Errorf("expected a page named %s", expected)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// IndexPage is the name of the HTML page that lists all of the operations.
+const IndexPage = "index.html"
+
+var htmlTemplate = template.Must(template.New("layout").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 60em; padding: 0 1em; }
+table { border-collapse: collapse; margin: 1em 0; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+pre { background: #f6f8fa; padding: 1em; overflow: auto; }
+.method { font-weight: bold; }
+.deprecated { color: #b00; }
+ul.schema { font-family: monospace; }
+</style>
+</head>
+<body>
+<nav><a href="` + IndexPage + `">{{ .Doc.Title }}</a>{{ range .Doc.Tags }} | <a href="{{ .File }}">{{ .Name }}</a>{{ end }}</nav>
+{{ template "body" . }}
+</body>
+</html>
+`))
+
+var htmlIndexTemplate = template.Must(template.Must(htmlTemplate.Clone()).Parse(`{{ define "body" }}{{ with .Doc }}
+<h1>{{ .Title }}</h1>
+{{ if .Version }}<p>Version: {{ .Version }}</p>{{ end }}
+{{ if .Description }}<p>{{ .Description }}</p>{{ end }}
+{{ if .Servers }}<h2>Servers</h2>
+<table>
+<tr><th>URL</th><th>Description</th></tr>
+{{ range .Servers }}<tr><td>{{ .URL }}</td><td>{{ .Description }}</td></tr>
+{{ end }}</table>{{ end }}
+<h2>Operations</h2>
+{{ range .Tags }}{{ $file := .File }}
+<h3><a href="{{ .File }}">{{ .Name }}</a></h3>
+{{ if .Description }}<p>{{ .Description }}</p>{{ end }}
+<table>
+<tr><th>Method</th><th>Path</th><th>Operation ID</th><th>Summary</th></tr>
+{{ range .Operations }}<tr><td class="method">{{ .Method }}</td><td><a href="{{ $file }}#{{ .Anchor }}">{{ .Path }}</a></td><td>{{ .OperationID }}</td><td>{{ .Summary }}</td></tr>
+{{ end }}</table>
+{{ end }}{{ end }}{{ end }}`))
+
+var htmlTagTemplate = template.Must(template.Must(htmlTemplate.Clone()).Parse(`{{ define "body" }}{{ with .Tag }}
+<h1>{{ .Name }}</h1>
+{{ if .Description }}<p>{{ .Description }}</p>{{ end }}
+<table>
+<tr><th>Method</th><th>Path</th><th>Operation ID</th><th>Summary</th></tr>
+{{ range .Operations }}<tr><td class="method">{{ .Method }}</td><td><a href="#{{ .Anchor }}">{{ .Path }}</a></td><td>{{ .OperationID }}</td><td>{{ .Summary }}</td></tr>
+{{ end }}</table>
+{{ range .Operations }}
+<section id="{{ .Anchor }}">
+<h2><span class="method">{{ .Method }}</span> {{ .Path }}</h2>
+{{ if .Deprecated }}<p class="deprecated">Deprecated</p>{{ end }}
+{{ if .Summary }}<p>{{ .Summary }}</p>{{ end }}
+{{ if .Description }}<p>{{ .Description }}</p>{{ end }}
+{{ if .Params }}<h3>Parameters</h3>
+<table>
+<tr><th>Name</th><th>In</th><th>Type</th><th>Required</th><th>Description</th></tr>
+{{ range .Params }}<tr><td>{{ .Name }}</td><td>{{ .In }}</td><td>{{ .Type }}</td><td>{{ if .Required }}yes{{ else }}no{{ end }}</td><td>{{ .Description }}</td></tr>
+{{ end }}</table>{{ end }}
+{{ if .RequestBody }}<h3>Request body</h3>
+{{ range .RequestBody }}{{ template "content" . }}{{ end }}{{ end }}
+{{ if .Responses }}<h3>Responses</h3>
+{{ range .Responses }}<h4>{{ .Status }}{{ if .Description }} {{ .Description }}{{ end }}</h4>
+{{ if .Headers }}<table>
+<tr><th>Header</th><th>Type</th><th>Required</th><th>Description</th></tr>
+{{ range .Headers }}<tr><td>{{ .Name }}</td><td>{{ .Type }}</td><td>{{ if .Required }}yes{{ else }}no{{ end }}</td><td>{{ .Description }}</td></tr>
+{{ end }}</table>{{ end }}
+{{ range .Content }}{{ template "content" . }}{{ end }}{{ end }}{{ end }}
+</section>
+{{ end }}{{ end }}{{ end }}
+{{ define "content" }}<p>Content type: <code>{{ .MediaType }}</code></p>
+{{ if .Fields }}<ul class="schema">
+{{ range .Fields }}<li style="margin-left: {{ .Depth }}em">{{ if .Name }}<code>{{ .Name }}</code> {{ end }}({{ .Type }}{{ if .Required }}, required{{ end }}{{ if .Deprecated }}, deprecated{{ end }}){{ if .Description }}: {{ .Description }}{{ end }}{{ if .Enum }} Enum: {{ .Enum }}.{{ end }}</li>
+{{ end }}</ul>{{ end }}
+{{ if .Example }}<pre><code{{ if .ExampleLanguage }} class="language-{{ .ExampleLanguage }}"{{ end }}>{{ .Example }}</code></pre>{{ end }}
+{{ end }}`))
+
+type htmlPage struct {
+	Title string
+	Doc   *document
+	Tag   *tagGroup
+}
+
+// HTML renders the spec as a static multi-page HTML site. The map key is the file name
+// of the page. The IndexPage lists every operation grouped by tag, and there is one
+// page per tag that documents its operations in full.
+func HTML(spec *openapi3.T) (pages map[string][]byte, err error) {
+	doc, err := newDocument(spec)
+	if err != nil {
+		return nil, err
+	}
+	pages = make(map[string][]byte)
+	var buf bytes.Buffer
+	if err = htmlIndexTemplate.Execute(&buf, htmlPage{Title: doc.Title, Doc: doc}); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", IndexPage, err)
+	}
+	pages[IndexPage] = buf.Bytes()
+	for _, tag := range doc.Tags {
+		var buf bytes.Buffer
+		if err = htmlTagTemplate.Execute(&buf, htmlPage{Title: doc.Title + " - " + tag.Name, Doc: doc, Tag: tag}); err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", tag.File, err)
+		}
+		pages[tag.File] = buf.Bytes()
+	}
+	return pages, nil
+}
+
+// WriteHTML renders the spec as a static multi-page HTML site into the dir directory.
+func WriteHTML(spec *openapi3.T, dir string) error {
+	pages, err := HTML(spec)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	for name, page := range pages {
+		if err = os.WriteFile(filepath.Join(dir, name), page, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
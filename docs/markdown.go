@@ -0,0 +1,112 @@
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var markdownFuncs = template.FuncMap{
+	"cell": markdownCell,
+	"yesno": func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	},
+}
+
+var markdownTemplate = template.Must(template.New("markdown").Funcs(markdownFuncs).Parse(`# {{ .Title }}
+{{ if .Version }}
+Version: {{ .Version }}
+{{ end }}{{ if .Description }}
+{{ .Description }}
+{{ end }}{{ if .Servers }}
+## Servers
+
+| URL | Description |
+| --- | --- |
+{{ range .Servers }}| {{ cell .URL }} | {{ cell .Description }} |
+{{ end }}{{ end }}
+## Operations
+{{ range .Tags }}
+### {{ .Name }}
+{{ if .Description }}
+{{ .Description }}
+{{ end }}
+| Method | Path | Operation ID | Summary |
+| --- | --- | --- | --- |
+{{ range .Operations }}| {{ .Method }} | [{{ cell .Path }}](#{{ .Anchor }}) | {{ cell .OperationID }} | {{ cell .Summary }} |
+{{ end }}{{ end }}
+{{- range .Operations }}
+{{ template "operation" . }}{{ end }}`))
+
+func init() {
+	template.Must(markdownTemplate.New("operation").Parse(`<a id="{{ .Anchor }}"></a>
+
+## {{ .Method }} {{ .Path }}
+{{ if .Deprecated }}
+**Deprecated**
+{{ end }}{{ if .Summary }}
+{{ .Summary }}
+{{ end }}{{ if .Description }}
+{{ .Description }}
+{{ end }}{{ if .Params }}
+### Parameters
+
+| Name | In | Type | Required | Description |
+| --- | --- | --- | --- | --- |
+{{ range .Params }}| {{ cell .Name }} | {{ .In }} | {{ cell .Type }} | {{ yesno .Required }} | {{ cell .Description }} |
+{{ end }}{{ end }}{{ if .RequestBody }}
+### Request body
+{{ range .RequestBody }}{{ template "content" . }}{{ end }}{{ end }}{{ if .Responses }}
+### Responses
+{{ range .Responses }}
+#### {{ .Status }}{{ if .Description }} {{ .Description }}{{ end }}
+{{ if .Headers }}
+| Header | Type | Required | Description |
+| --- | --- | --- | --- |
+{{ range .Headers }}| {{ cell .Name }} | {{ cell .Type }} | {{ yesno .Required }} | {{ cell .Description }} |
+{{ end }}{{ end }}{{ range .Content }}{{ template "content" . }}{{ end }}{{ end }}{{ end }}`))
+
+	template.Must(markdownTemplate.New("content").Parse(`
+Content type: ` + "`{{ .MediaType }}`" + `
+{{ if .Fields }}
+{{ range .Fields }}{{ .Indent }}- {{ if .Name }}` + "`{{ .Name }}`" + ` {{ end }}({{ .Type }}{{ if .Required }}, required{{ end }}{{ if .Deprecated }}, deprecated{{ end }}){{ if .Description }}: {{ .Description }}{{ end }}{{ if .Enum }} Enum: {{ .Enum }}.{{ end }}
+{{ end }}{{ end }}{{ if .Example }}
+Example:
+
+` + "```{{ .ExampleLanguage }}" + `
+{{ .Example }}
+` + "```" + `
+{{ end }}`))
+}
+
+// Markdown renders the spec as a single Markdown document. Operations are listed
+// in tables grouped by tag, followed by the parameters, request and response
+// schemas, and example payloads of each operation.
+func Markdown(spec *openapi3.T) ([]byte, error) {
+	doc, err := newDocument(spec)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err = markdownTemplate.Execute(&buf, doc); err != nil {
+		return nil, fmt.Errorf("failed to render markdown: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+var markdownCellEscaper = strings.NewReplacer(
+	"|", `\|`,
+	"\r\n", "<br>",
+	"\n", "<br>",
+)
+
+// markdownCell escapes the value so that it can be placed in a Markdown table cell.
+func markdownCell(s string) string {
+	return markdownCellEscaper.Replace(s)
+}
@@ -0,0 +1,134 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SpecError is a problem found while creating the OpenAPI specification.
+type SpecError struct {
+	// Method of the route with the problem, if the problem is in a route.
+	Method Method
+	// Pattern of the route with the problem, if the problem is in a route.
+	Pattern Pattern
+	// Status of the response with the problem, or zero.
+	Status int
+	// FieldPath is the path to the Go type or field with the problem, e.g. Body[Topic].Data.Tags[].
+	// Slice and array elements are written as [], and map values as {}.
+	FieldPath string
+	// Err is the problem.
+	Err error
+}
+
+// Error returns the problem, prefixed with where it was found, e.g.
+// GET /topic/{id} 200 → Body[Topic].Data.Tags[]: unsupported type chan int
+func (e *SpecError) Error() string {
+	var sb strings.Builder
+	if e.Method != "" || e.Pattern != "" {
+		sb.WriteString(string(e.Method) + " " + string(e.Pattern))
+	}
+	if e.Status != 0 {
+		fmt.Fprintf(&sb, " %d", e.Status)
+	}
+	if e.FieldPath != "" {
+		if sb.Len() > 0 {
+			sb.WriteString(" → ")
+		}
+		sb.WriteString(e.FieldPath)
+	}
+	if sb.Len() > 0 {
+		sb.WriteString(": ")
+	}
+	sb.WriteString(e.Err.Error())
+	return sb.String()
+}
+
+func (e *SpecError) Unwrap() error {
+	return e.Err
+}
+
+// SpecErrors are all of the problems found while creating the OpenAPI specification.
+// Each problem is a *SpecError, which can be found with errors.As.
+type SpecErrors []*SpecError
+
+// Error returns the problems, one per line.
+func (e SpecErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e SpecErrors) Unwrap() []error {
+	op := make([]error, len(e))
+	for i, err := range e {
+		op[i] = err
+	}
+	return op
+}
+
+// sort the problems by route, status and field, so that they're reported in a stable order.
+func (e SpecErrors) sort() {
+	sort.SliceStable(e, func(i, j int) bool {
+		a, b := e[i], e[j]
+		if a.Pattern != b.Pattern {
+			return a.Pattern < b.Pattern
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		if a.Status != b.Status {
+			return a.Status < b.Status
+		}
+		return a.FieldPath < b.FieldPath
+	})
+}
+
+// fieldError is a problem in the schema of a field of a model, e.g. .Data.Tags[].
+type fieldError struct {
+	path string
+	err  error
+}
+
+func (e *fieldError) Error() string {
+	return e.path + ": " + e.err.Error()
+}
+
+func (e *fieldError) Unwrap() error {
+	return e.err
+}
+
+// withFieldPath prefixes the path of the field that has the problem with the segment, e.g. .Data.
+func withFieldPath(segment string, err error) error {
+	if fe, ok := err.(*fieldError); ok {
+		return &fieldError{path: segment + fe.path, err: fe.err}
+	}
+	return &fieldError{path: segment, err: err}
+}
+
+// splitFieldError returns the path to the field of the model that has the problem, and the problem.
+func splitFieldError(model reflect.Type, err error) (fieldPath string, inner error) {
+	if model == nil {
+		return "", err
+	}
+	if fe, ok := err.(*fieldError); ok {
+		return shortTypeName(model) + fe.path, fe.err
+	}
+	return shortTypeName(model), err
+}
+
+var (
+	packagePathPrefix = regexp.MustCompile(`[\w.\-]+/`)
+	packageQualifier  = regexp.MustCompile(`\w+\.`)
+)
+
+// shortTypeName returns the name of the type without package paths, e.g. Body[Topic] for
+// models.Body[github.com/example/models.Topic].
+func shortTypeName(t reflect.Type) string {
+	name := packagePathPrefix.ReplaceAllString(t.String(), "")
+	return packageQualifier.ReplaceAllString(name, "")
+}
@@ -0,0 +1,155 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/go-cmp/cmp"
+)
+
+type DiagnosticsBody[T any] struct {
+	Data T `json:"data"`
+}
+
+type DiagnosticsTopic struct {
+	Tags []chan int `json:"tags"`
+}
+
+// DiagnosticsMoney marshals itself, but doesn't customise its schema.
+type DiagnosticsMoney struct {
+	cents int64
+}
+
+func (m DiagnosticsMoney) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.cents)
+}
+
+func TestSpecErrors(t *testing.T) {
+	api := NewAPI("diagnostics")
+	api.Get("/topic/{id}").
+		HasResponseModel(http.StatusOK, ModelOf[DiagnosticsBody[DiagnosticsTopic]]())
+	api.Post("/topic").
+		HasRequestModel(ModelOf[map[int]string]()).
+		HasResponseModel(http.StatusCreated, ModelOf[string]())
+	api.Get("/topics").
+		HasQueryParameter("since", QueryParam{Model: ModelOf[func()]()}).
+		HasResponseModel(http.StatusOK, ModelOf[string]())
+	api.Post("/upload").
+		HasRequestContent(ContentTypeMultipartForm, ModelOf[FormLogin]()).
+		HasRequestEncoding(ContentTypeMultipartForm, "avatar", &openapi3.Encoding{ContentType: "image/png"}).
+		HasRequestContent(ContentTypeURLEncodedForm, ModelOf[string]()).
+		HasResponseModel(http.StatusCreated, ModelOf[string]())
+	api.Get("/wallet").
+		HasResponseModel(http.StatusOK, ModelOf[map[string]DiagnosticsMoney]())
+
+	_, err := api.Spec()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	var errs SpecErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected SpecErrors, got %T", err)
+	}
+	expected := []string{
+		"POST /topic → map[int]string: request model: maps must have a string key, but this map is of type \"int\"",
+		"GET /topic/{id} 200 → DiagnosticsBody[DiagnosticsTopic].Data.Tags[]: unsupported type chan int",
+		"GET /topics → func(): query parameter \"since\": unsupported type func()",
+		"POST /upload → FormLogin: request model: encoding of \"avatar\": the property is not in the model",
+		"POST /upload → string: request model: unsupported type string: form models must be structs",
+		"GET /wallet 200 → map[string]DiagnosticsMoney{}: openapi.DiagnosticsMoney implements json.Marshaler, so its schema can't be created from its fields: add it to KnownTypes, or implement ApplyCustomSchema or OpenAPISchema",
+	}
+	var actual []string
+	for _, e := range errs {
+		actual = append(actual, e.Error())
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	var specErr *SpecError
+	if !errors.As(err, &specErr) {
+		t.Fatal("expected errors.As to find a *SpecError")
+	}
+	if specErr.Method != http.MethodPost || specErr.Pattern != "/topic" {
+		t.Errorf("expected the first problem to be in POST /topic, got %s %s", specErr.Method, specErr.Pattern)
+	}
+}
+
+func TestSpecErrorsIncludeValidationContext(t *testing.T) {
+	api := NewAPI("diagnostics")
+	api.Get("/ok").HasResponseModel(http.StatusOK, ModelOf[string]())
+	api.Delete("/topic/{id}")
+
+	_, err := api.Spec()
+	var errs SpecErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected SpecErrors, got %v", err)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 problem, got %d: %v", len(errs), err)
+	}
+	if errs[0].Method != http.MethodDelete || errs[0].Pattern != "/topic/{id}" {
+		t.Errorf("expected the problem to be in DELETE /topic/{id}, got %v", errs[0])
+	}
+}
+
+type DiagnosticsAccount struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// DiagnosticsAccountInput has the name of the request component of DiagnosticsAccount.
+type DiagnosticsAccountInput struct {
+	Name string `json:"name"`
+}
+
+func TestSpecErrorsDirectionalNameClash(t *testing.T) {
+	api := NewAPI("diagnostics", WithDirectionalModels())
+	api.StripPkgPaths = []string{"github.com/ihezebin/openapi"}
+	Customize(api, func(f *Fields[DiagnosticsAccount], a *DiagnosticsAccount) {
+		f.Of(&a.ID).ReadOnly()
+	})
+	api.Get("/account").HasResponseModel(http.StatusOK, ModelOf[DiagnosticsAccount]())
+	api.Post("/account").
+		HasRequestModel(ModelOf[DiagnosticsAccountInput]()).
+		HasResponseModel(http.StatusCreated, ModelOf[DiagnosticsAccount]())
+
+	_, err := api.Spec()
+	var errs SpecErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected SpecErrors, got %v", err)
+	}
+	expected := []string{
+		"the request and response components of DiagnosticsAccount can't be created, because the name DiagnosticsAccountInput is already used by another component: rename one of the models",
+	}
+	var actual []string
+	for _, e := range errs {
+		actual = append(actual, e.Error())
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestShortTypeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		actual   string
+		expected string
+	}{
+		{name: "generic", actual: shortTypeName(reflect.TypeFor[DiagnosticsBody[*DiagnosticsTopic]]()), expected: "DiagnosticsBody[*DiagnosticsTopic]"},
+		{name: "slice", actual: shortTypeName(reflect.TypeFor[[]DiagnosticsTopic]()), expected: "[]DiagnosticsTopic"},
+		{name: "map", actual: shortTypeName(reflect.TypeFor[map[string]float64]()), expected: "map[string]float64"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, test.actual)
+			}
+		})
+	}
+}
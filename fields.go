@@ -0,0 +1,197 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unsafe"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Customize customises the schemas of the fields of T. The function is called with a value of T,
+// so that fields are identified by their address, instead of their name, and renaming a field
+// can't break its customisation. If it's called after the spec has been created, the models
+// are created again by the next call to Spec.
+// Example:
+//
+//	openapi.Customize(api, func(f *openapi.Fields[User], u *User) {
+//		f.Of(&u.Email).Format("email").Example("alice@example.com")
+//		f.Of(&u.Age).Min(0).Max(150)
+//	})
+//
+// It panics if a field isn't an exported field of T, or of a struct embedded in T. The fields of
+// embedded structs with a JSON name, e.g. `json:"base"`, are encoded in a property of their own,
+// so they can't be customised either.
+func Customize[T any](api *API, fn func(f *Fields[T], v *T)) {
+	v := new(T)
+	f := &Fields[T]{
+		t:    reflect.TypeFor[T](),
+		base: uintptr(unsafe.Pointer(v)),
+	}
+	if f.t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("openapi: can't customise the fields of %v, since it isn't a struct", f.t))
+	}
+	fn(f, v)
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.invalidate()
+	api.rebuildModels = true
+	if api.fieldSchemas == nil {
+		api.fieldSchemas = make(map[reflect.Type][]*FieldSchema)
+	}
+	api.fieldSchemas[f.t] = append(api.fieldSchemas[f.t], f.fields...)
+}
+
+// Fields identifies the fields of T, to customise their schemas.
+type Fields[T any] struct {
+	t      reflect.Type
+	base   uintptr
+	fields []*FieldSchema
+}
+
+// Of returns the schema of a field, given a pointer to the field of the value passed to the
+// function of Customize.
+func (f *Fields[T]) Of(field any) *FieldSchema {
+	p := reflect.ValueOf(field)
+	if p.Kind() != reflect.Pointer || p.IsNil() {
+		panic(fmt.Sprintf("openapi: Of must be passed a pointer to a field of %v, not %T", f.t, field))
+	}
+	offset := p.Pointer() - f.base
+	sf, ok := fieldAtOffset(f.t, offset, p.Type().Elem())
+	if !ok {
+		panic(fmt.Sprintf("openapi: Of must be passed a pointer to a field of %v, but %T isn't a field of it", f.t, field))
+	}
+	name, ok := jsonFieldName(sf)
+	if !ok || !sf.IsExported() {
+		panic(fmt.Sprintf("openapi: field %s of %v isn't encoded as JSON, so can't be customised", sf.Name, f.t))
+	}
+	fs := &FieldSchema{name: name}
+	f.fields = append(f.fields, fs)
+	return fs
+}
+
+// fieldAtOffset returns the field of type ft at the offset of t, including the fields of
+// embedded structs that don't have a JSON name, since they're encoded as fields of t.
+func fieldAtOffset(t reflect.Type, offset uintptr, ft reflect.Type) (f reflect.StructField, ok bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f = t.Field(i)
+		// Zero size fields share their offset with the next field, and are told apart by type.
+		if offset < f.Offset || offset >= f.Offset+max(f.Type.Size(), 1) {
+			continue
+		}
+		if f.Offset == offset && f.Type == ft {
+			return f, true
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && strings.Split(f.Tag.Get("json"), ",")[0] == "" {
+			if f, ok = fieldAtOffset(f.Type, offset-f.Offset, ft); ok {
+				return f, true
+			}
+		}
+	}
+	return f, false
+}
+
+// jsonFieldName returns the name of the field in JSON, and false if it isn't encoded.
+func jsonFieldName(f reflect.StructField) (name string, ok bool) {
+	jsonTags := strings.Split(f.Tag.Get("json"), ",")
+	name = jsonTags[0]
+	// Fields tagged with json:"-" aren't encoded.
+	if name == "-" && len(jsonTags) == 1 {
+		return name, false
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name, true
+}
+
+// FieldSchema customises the schema of a field. Its methods return the FieldSchema, so that
+// they can be chained.
+type FieldSchema struct {
+	name  string
+	apply []func(s *openapi3.Schema)
+}
+
+// Apply customises the schema of the field with a function.
+func (fs *FieldSchema) Apply(f func(s *openapi3.Schema)) *FieldSchema {
+	fs.apply = append(fs.apply, f)
+	return fs
+}
+
+// Description sets the description of the field.
+func (fs *FieldSchema) Description(description string) *FieldSchema {
+	return fs.Apply(func(s *openapi3.Schema) {
+		s.Description = description
+	})
+}
+
+// Format sets the format of the field, e.g. "email".
+func (fs *FieldSchema) Format(format string) *FieldSchema {
+	return fs.Apply(func(s *openapi3.Schema) {
+		s.Format = format
+	})
+}
+
+// Example sets an example value of the field.
+func (fs *FieldSchema) Example(example any) *FieldSchema {
+	return fs.Apply(func(s *openapi3.Schema) {
+		s.Example = example
+	})
+}
+
+// Pattern sets the regular expression that the field must match.
+func (fs *FieldSchema) Pattern(pattern string) *FieldSchema {
+	return fs.Apply(func(s *openapi3.Schema) {
+		s.Pattern = pattern
+	})
+}
+
+// Min sets the minimum value of the field.
+func (fs *FieldSchema) Min(min float64) *FieldSchema {
+	return fs.Apply(func(s *openapi3.Schema) {
+		s.Min = &min
+	})
+}
+
+// Max sets the maximum value of the field.
+func (fs *FieldSchema) Max(max float64) *FieldSchema {
+	return fs.Apply(func(s *openapi3.Schema) {
+		s.Max = &max
+	})
+}
+
+// Enum sets the values that the field can have.
+func (fs *FieldSchema) Enum(values ...any) *FieldSchema {
+	return fs.Apply(func(s *openapi3.Schema) {
+		s.Enum = values
+	})
+}
+
+// Deprecated marks the field as deprecated.
+func (fs *FieldSchema) Deprecated() *FieldSchema {
+	return fs.Apply(func(s *openapi3.Schema) {
+		s.Deprecated = true
+	})
+}
+
+// ReadOnly marks the field as only being sent in responses.
+func (fs *FieldSchema) ReadOnly() *FieldSchema {
+	return fs.Apply(func(s *openapi3.Schema) {
+		s.ReadOnly = true
+	})
+}
+
+// WriteOnly marks the field as only being sent in requests, e.g. a password.
+func (fs *FieldSchema) WriteOnly() *FieldSchema {
+	return fs.Apply(func(s *openapi3.Schema) {
+		s.WriteOnly = true
+	})
+}
+
+func (fs *FieldSchema) applyTo(s *openapi3.Schema) {
+	for _, f := range fs.apply {
+		f(s)
+	}
+}
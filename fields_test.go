@@ -0,0 +1,66 @@
+package openapi
+
+import (
+	"testing"
+)
+
+// CustomizeNamedEmbed embeds a struct with a JSON name, so its fields are encoded in the
+// "audit" property, rather than as fields of CustomizeNamedEmbed.
+type CustomizeNamedEmbed struct {
+	CustomizeAudit `json:"audit"`
+	Name           string `json:"name"`
+}
+
+func TestCustomizePanics(t *testing.T) {
+	var other CustomizeUser
+	tests := []struct {
+		name      string
+		customise func(f *Fields[CustomizeUser], u *CustomizeUser)
+	}{
+		{
+			name: "fields of other values",
+			customise: func(f *Fields[CustomizeUser], u *CustomizeUser) {
+				f.Of(&other.Email)
+			},
+		},
+		{
+			name: "fields of nested structs",
+			customise: func(f *Fields[CustomizeUser], u *CustomizeUser) {
+				f.Of(&u.Home.Line1)
+			},
+		},
+		{
+			name: "fields that aren't encoded",
+			customise: func(f *Fields[CustomizeUser], u *CustomizeUser) {
+				f.Of(&u.Ignored)
+			},
+		},
+		{
+			name: "values that aren't pointers",
+			customise: func(f *Fields[CustomizeUser], u *CustomizeUser) {
+				f.Of(u.Email)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			Customize(NewAPI("users"), test.customise)
+		})
+	}
+}
+
+func TestCustomizeNamedEmbeddedStructPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	Customize(NewAPI("users"), func(f *Fields[CustomizeNamedEmbed], e *CustomizeNamedEmbed) {
+		f.Of(&e.CreatedBy)
+	})
+}
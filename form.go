@@ -0,0 +1,127 @@
+package openapi
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	// ContentTypeMultipartForm is the media type of a multipart form, used to upload files.
+	ContentTypeMultipartForm = "multipart/form-data"
+	// ContentTypeURLEncodedForm is the media type of an HTML form.
+	ContentTypeURLEncodedForm = "application/x-www-form-urlencoded"
+	// ContentTypeOctetStream is the media type of binary data, e.g. a file download.
+	ContentTypeOctetStream = "application/octet-stream"
+)
+
+// binaryTypes are documented as binary data, e.g. the content of an uploaded file.
+var binaryTypes = []reflect.Type{
+	reflect.TypeFor[io.Reader](),
+	reflect.TypeFor[io.ReadCloser](),
+	reflect.TypeFor[*os.File](),
+	reflect.TypeFor[multipart.File](),
+	reflect.TypeFor[*multipart.FileHeader](),
+}
+
+func newBinarySchema() *openapi3.Schema {
+	return openapi3.NewStringSchema().WithFormat("binary")
+}
+
+// isBinaryType returns true for byte slices and file types, which are sent as they are in
+// multipart forms and in request and response bodies that aren't JSON.
+func isBinaryType(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 || slices.Contains(binaryTypes, t)
+}
+
+// isFormMediaType returns true for multipart/form-data and application/x-www-form-urlencoded.
+func isFormMediaType(mediaType string) bool {
+	mt, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		mt = mediaType
+	}
+	return mt == ContentTypeMultipartForm || mt == ContentTypeURLEncodedForm
+}
+
+// isJSONMediaType returns true for application/json, and media types with a +json suffix,
+// e.g. application/merge-patch+json.
+func isJSONMediaType(mediaType string) bool {
+	mt, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		mt = mediaType
+	}
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
+
+// registerFormModel registers the schema of a struct that's sent as a form, using the form
+// struct tags of its fields. Fields without a form tag use the name of the field. The struct is
+// registered with a Form suffix, e.g. UploadForm, since its schema differs from the JSON schema
+// of the type.
+//
+// In multipart forms, byte slices and file types such as *multipart.FileHeader are documented as
+// binary file parts.
+func (api *API) registerFormModel(mediaType string, model Model) (name string, schema *openapi3.Schema, err error) {
+	t := model.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return "", nil, fmt.Errorf("unsupported type %v: form models must be structs", t)
+	}
+	multipart := strings.HasPrefix(mediaType, ContentTypeMultipartForm)
+	name = api.getModelName(t) + "Form"
+	if !multipart {
+		name = api.getModelName(t) + "URLEncodedForm"
+	}
+	if schema, ok := api.models[name]; ok {
+		return name, schema, nil
+	}
+	schema = openapi3.NewObjectSchema()
+	schema.Properties = make(openapi3.Schemas)
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		tags := strings.Split(f.Tag.Get("form"), ",")
+		fieldName := tags[0]
+		if fieldName == "-" {
+			continue
+		}
+		if fieldName == "" {
+			fieldName = f.Name
+		}
+		var ref *openapi3.SchemaRef
+		switch {
+		case multipart && isBinaryType(f.Type):
+			ref = newBinarySchema().NewRef()
+		case multipart && f.Type.Kind() == reflect.Slice && isBinaryType(f.Type.Elem()):
+			ref = openapi3.NewArraySchema().WithItems(newBinarySchema()).NewRef()
+		default:
+			fieldSchemaName, fieldSchema, err := api.registerModel(modelFromType(f.Type))
+			if err != nil {
+				return name, schema, withFieldPath("."+f.Name, err)
+			}
+			ref = getSchemaReferenceOrValue(fieldSchemaName, fieldSchema)
+		}
+		if ref.Value != nil && len(f.Index) == 1 {
+			value := *ref.Value
+			if value.Description, value.Deprecated, err = api.getTypeFieldComment(t.PkgPath(), t.Name(), f.Name); err != nil {
+				return name, schema, withFieldPath("."+f.Name, fmt.Errorf("failed to get comments: %w", err))
+			}
+			ref = value.NewRef()
+		}
+		schema.Properties[fieldName] = ref
+		if isFieldRequired(f.Type.Kind() == reflect.Pointer && !slices.Contains(binaryTypes, f.Type), slices.Contains(tags, "omitempty")) {
+			schema.Required = append(schema.Required, fieldName)
+		}
+	}
+	api.models[name] = schema
+	return name, schema, nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/ihezebin/openapi/docs"
+)
+
+var (
+	flagSpec   = flag.String("spec", "", "The OpenAPI specification file (JSON or YAML) to render, e.g. swagger.json")
+	flagFormat = flag.String("format", "markdown", "The output format, markdown or html")
+	flagOutput = flag.String("output", "", "The output file for markdown (defaults to stdout), or the output directory for html")
+)
+
+func main() {
+	flag.Parse()
+	if *flagSpec == "" {
+		fmt.Fprintln(os.Stderr, "the -spec flag is required")
+		flag.Usage()
+		os.Exit(1)
+	}
+	spec, err := openapi3.NewLoader().LoadFromFile(*flagSpec)
+	if err != nil {
+		log.Fatalf("failed to load spec: %v", err)
+	}
+	switch *flagFormat {
+	case "markdown", "md":
+		md, err := docs.Markdown(spec)
+		if err != nil {
+			log.Fatalf("failed to render markdown: %v", err)
+		}
+		if *flagOutput == "" {
+			os.Stdout.Write(md)
+			return
+		}
+		if err = os.WriteFile(*flagOutput, md, 0o644); err != nil {
+			log.Fatalf("failed to write markdown: %v", err)
+		}
+	case "html":
+		if *flagOutput == "" {
+			log.Fatal("the -output directory is required for html")
+		}
+		if err = docs.WriteHTML(spec, *flagOutput); err != nil {
+			log.Fatalf("failed to render html: %v", err)
+		}
+	default:
+		log.Fatalf("unknown format %q, expected markdown or html", *flagFormat)
+	}
+}
@@ -0,0 +1,828 @@
+// Package goclient generates a typed Go client for an openapi.API.
+//
+// The client is generated from the API rather than from its OpenAPI specification,
+// so it reuses the Go types of the request and response models. Requests sent by the
+// client and responses read by it are exactly the types used by the server.
+package goclient
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"go/format"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/ihezebin/openapi"
+)
+
+// GenerateOpts configures the generated client.
+type GenerateOpts func(*generator)
+
+// WithPackageName sets the package name of the generated code, which defaults to "client".
+func WithPackageName(name string) GenerateOpts {
+	return func(g *generator) {
+		g.pkg = name
+	}
+}
+
+// Generate the source of a Go client package for the API.
+//
+// Each route becomes a method of the client. Methods are named from the OperationID of the
+// route, or from the method and pattern if it isn't set. Path, query, header and cookie
+// parameters are fields of a params struct that is generated for each method. Struct and map query
+// parameters are sent as their properties, using the style of the parameter. The method returns the
+// model of the first successful (2xx) response, and an *Error for other status codes.
+func Generate(api *openapi.API, opts ...GenerateOpts) ([]byte, error) {
+	g := &generator{
+		pkg:     "client",
+		imports: newImports(),
+	}
+	for _, o := range opts {
+		o(g)
+	}
+	f := file{
+		Package: g.pkg,
+		Title:   api.Name,
+	}
+	names := map[string]string{}
+	for _, r := range api.SortedRoutes() {
+		m, err := g.method(r)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", r.Method, r.Pattern, err)
+		}
+		route := fmt.Sprintf("%s %s", r.Method, r.Pattern)
+		if existing, ok := names[m.Name]; ok {
+			return nil, fmt.Errorf("%s: method name %q is already used by %s, set a unique operation ID", route, m.Name, existing)
+		}
+		names[m.Name] = route
+		f.Methods = append(f.Methods, m)
+	}
+	f.Imports, f.ThirdPartyImports = g.imports.list()
+
+	var buf bytes.Buffer
+	if err := clientTemplate.Execute(&buf, f); err != nil {
+		return nil, fmt.Errorf("render client err: %w", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format client err: %w", err)
+	}
+	return src, nil
+}
+
+type generator struct {
+	pkg     string
+	imports *imports
+}
+
+type file struct {
+	Package string
+	Title   string
+	Imports []importSpec
+	// ThirdPartyImports are the packages of the models that aren't in the standard library.
+	ThirdPartyImports []importSpec
+	Methods           []*method
+}
+
+type method struct {
+	Name       string
+	Method     string
+	Pattern    string
+	Summary    string
+	Doc        string
+	Deprecated bool
+	// Params is the name of the params struct, empty if the route has no parameters.
+	Params      string
+	Fields      []*field
+	Path        string
+	RequestType string
+	// ResultType is empty if the route doesn't have a successful response model.
+	ResultType     string
+	ResultStatuses []int
+	Errors         []errorResponse
+	// DefaultError is the model of the default response.
+	DefaultError string
+}
+
+func (m *method) HasQuery() bool  { return m.hasIn("query") }
+func (m *method) HasHeader() bool { return m.hasIn("header") || m.hasIn("cookie") }
+
+func (m *method) hasIn(in string) bool {
+	for _, f := range m.Fields {
+		if f.In == in {
+			return true
+		}
+	}
+	return false
+}
+
+type field struct {
+	Name     string
+	Param    string
+	In       string
+	Type     string
+	Optional bool
+	Doc      string
+	// Array is set for slices and arrays, which are sent as several values, or joined with Sep.
+	Array bool
+	// Sep joins the values of arrays that aren't exploded, and is empty if they're exploded.
+	Sep string
+	// Prefix is written before the value of a path parameter, e.g. ";id=" for the matrix style.
+	Prefix string
+	// Object is set for structs and maps in the query, which are sent as their properties using
+	// Style and Explode, e.g. ?filter[status]=open in the deepObject style.
+	Object  bool
+	Style   string
+	Explode bool
+}
+
+// Value is an expression that formats the value of the field as a string, or as a []string for arrays.
+func (f *field) Value() string {
+	v := "params." + f.Name
+	if f.Array {
+		return "formatParams(" + v + ")"
+	}
+	if f.Optional {
+		v = "*" + v
+	}
+	if f.Type == "string" {
+		return v
+	}
+	return "formatParam(" + v + ")"
+}
+
+// Joined is an expression that formats the value of the field as a string, joining the values of arrays.
+func (f *field) Joined() string {
+	if f.Array {
+		return "strings.Join(" + f.Value() + ", " + strconv.Quote(f.Sep) + ")"
+	}
+	return f.Value()
+}
+
+// PathValue is an expression that formats the value of a path parameter, escaping its values.
+func (f *field) PathValue() string {
+	if !f.Array && f.Prefix == "" {
+		return "url.PathEscape(" + f.Value() + ")"
+	}
+	values := f.Value()
+	if !f.Array {
+		values = "[]string{" + values + "}"
+	}
+	return "joinPathParams(" + values + ", " + strconv.Quote(f.Prefix) + ", " + strconv.Quote(f.Sep) + ")"
+}
+
+type errorResponse struct {
+	Status int
+	Type   string
+}
+
+func (g *generator) method(r *openapi.Route) (m *method, err error) {
+	m = &method{
+		Name:       methodName(r),
+		Method:     string(r.Method),
+		Pattern:    string(r.Pattern),
+		Summary:    r.Summary,
+		Doc:        r.Description,
+		Deprecated: r.Deprecated,
+	}
+	params := r.Params
+	if params.Path, err = r.PathParams(); err != nil {
+		return nil, err
+	}
+	if m.Fields, err = g.paramFields(params); err != nil {
+		return nil, err
+	}
+	if len(m.Fields) > 0 {
+		m.Params = m.Name + "Params"
+	}
+	if m.Path, err = pathExpr(string(r.Pattern), m.Fields); err != nil {
+		return nil, err
+	}
+	if r.Models.Request.Type != nil {
+		if m.RequestType, err = g.typeName(r.Models.Request.Type); err != nil {
+			return nil, fmt.Errorf("request model: %w", err)
+		}
+	}
+
+	statuses := make([]int, 0, len(r.Models.Responses))
+	for status, model := range r.Models.Responses {
+		if model.Type != nil {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Ints(statuses)
+	var resultType reflect.Type
+	for _, status := range statuses {
+		t := r.Models.Responses[status].Type
+		name, err := g.typeName(t)
+		if err != nil {
+			return nil, fmt.Errorf("response model %d: %w", status, err)
+		}
+		if isSuccess(status) && (resultType == nil || resultType == t) {
+			resultType = t
+			m.ResultType = name
+			m.ResultStatuses = append(m.ResultStatuses, status)
+			continue
+		}
+		if status == 0 {
+			m.DefaultError = name
+			continue
+		}
+		m.Errors = append(m.Errors, errorResponse{Status: status, Type: name})
+	}
+	return m, nil
+}
+
+func isSuccess(status int) bool {
+	return status >= 200 && status < 300
+}
+
+func (g *generator) paramFields(params openapi.Params) (op []*field, err error) {
+	add := func(in, name string, p param) error {
+		f, err := g.paramField(in, name, p)
+		if err != nil {
+			return fmt.Errorf("%s parameter %q: %w", in, name, err)
+		}
+		op = append(op, f)
+		return nil
+	}
+	for _, name := range sortedKeys(params.Path) {
+		p := params.Path[name]
+		if err = add("path", name, param{Type: p.Type, Model: p.Model, Required: true, Style: p.Style, Explode: p.Explode, Description: p.Description}); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range sortedKeys(params.Query) {
+		p := params.Query[name]
+		if err = add("query", name, param{Type: p.Type, Model: p.Model, Required: p.Required, Style: p.Style, Explode: p.Explode, Description: p.Description}); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range sortedKeys(params.Header) {
+		p := params.Header[name]
+		if err = add("header", name, param{Type: p.Type, Model: p.Model, Required: p.Required, Explode: p.Explode, Description: p.Description}); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range sortedKeys(params.Cookie) {
+		p := params.Cookie[name]
+		if err = add("cookie", name, param{Type: p.Type, Model: p.Model, Required: p.Required, Explode: p.Explode, Description: p.Description}); err != nil {
+			return nil, err
+		}
+	}
+	seen := map[string]*field{}
+	for _, f := range op {
+		if existing, ok := seen[f.Name]; ok {
+			return nil, fmt.Errorf("%s parameter %q and %s parameter %q both map to the field %s", existing.In, existing.Param, f.In, f.Param, f.Name)
+		}
+		seen[f.Name] = f
+	}
+	return op, nil
+}
+
+// param is a path, query, header or cookie parameter.
+type param struct {
+	Type        openapi.PrimitiveType
+	Model       openapi.Model
+	Required    bool
+	Style       string
+	Explode     *bool
+	Description string
+}
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+// paramField returns the field of the params struct for the parameter. Its type is the type of the
+// model of the parameter, if it has one, and arrays are sent using the style of the parameter.
+func (g *generator) paramField(in, name string, p param) (f *field, err error) {
+	f = &field{Name: exportedName(name), Param: name, In: in, Type: goType(p.Type), Optional: !p.Required, Doc: p.Description}
+	t := p.Model.Type
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t != nil {
+		if f.Type, err = g.typeName(t); err != nil {
+			return nil, err
+		}
+		isText := t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)
+		switch {
+		case isText:
+		case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+			f.Array = true
+			// Nil slices aren't sent, so they don't need to be pointers.
+			f.Optional = false
+		case (t.Kind() == reflect.Struct || t.Kind() == reflect.Map) && in != "query":
+			return nil, fmt.Errorf("object parameters of type %v aren't supported by the client", t)
+		case t.Kind() == reflect.Struct || t.Kind() == reflect.Map:
+			f.Object = true
+			// Nil maps aren't sent, so they don't need to be pointers.
+			f.Optional = f.Optional && t.Kind() == reflect.Struct
+		}
+	}
+	style, explode := openapi.ParamStyle(in, p.Style, p.Explode)
+	f.Style, f.Explode = style, explode
+	f.Sep = ","
+	switch style {
+	case "form":
+		if explode && in == "query" {
+			f.Sep = ""
+		}
+	case "spaceDelimited":
+		f.Sep = " "
+	case "pipeDelimited":
+		f.Sep = "|"
+	case "label":
+		f.Prefix = "."
+		if explode {
+			f.Sep = "."
+		}
+	case "matrix":
+		f.Prefix = ";" + name + "="
+		if explode {
+			f.Sep = f.Prefix
+		}
+	}
+	return f, nil
+}
+
+func sortedKeys[V any](m map[string]V) (op []string) {
+	for k := range m {
+		op = append(op, k)
+	}
+	sort.Strings(op)
+	return op
+}
+
+func goType(t openapi.PrimitiveType) string {
+	switch t {
+	case openapi.PrimitiveTypeBool:
+		return "bool"
+	case openapi.PrimitiveTypeInteger:
+		return "int"
+	case openapi.PrimitiveTypeFloat64:
+		return "float64"
+	}
+	return "string"
+}
+
+// pathExpr returns a Go expression that builds the path of the route from the fields of the params.
+func pathExpr(pattern string, fields []*field) (string, error) {
+	params := map[string]*field{}
+	for _, f := range fields {
+		if f.In == "path" {
+			params[f.Param] = f
+		}
+	}
+	var parts []string
+	var literal strings.Builder
+	for len(pattern) > 0 {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			literal.WriteString(pattern)
+			break
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated path parameter in %q", pattern)
+		}
+		literal.WriteString(pattern[:start])
+		name := pattern[start+1 : start+end]
+		// Strip routers' regular expressions, e.g. {id:[0-9]+}.
+		name, _, _ = strings.Cut(name, ":")
+		f, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("path parameter %q is not declared", name)
+		}
+		if literal.Len() > 0 {
+			parts = append(parts, strconv.Quote(literal.String()))
+			literal.Reset()
+		}
+		parts = append(parts, f.PathValue())
+		pattern = pattern[start+end+1:]
+	}
+	if literal.Len() > 0 {
+		parts = append(parts, strconv.Quote(literal.String()))
+	}
+	if len(parts) == 0 {
+		return `"/"`, nil
+	}
+	return strings.Join(parts, " + "), nil
+}
+
+// methodName returns the OperationID of the route as an exported name, or a name based on the
+// method and pattern, e.g. GET /users/{id} becomes GetUsersByID.
+func methodName(r *openapi.Route) string {
+	if r.OperationID != "" {
+		return exportedName(r.OperationID)
+	}
+	var sb strings.Builder
+	sb.WriteString(exportedName(strings.ToLower(string(r.Method))))
+	segments := strings.Split(strings.Trim(string(r.Pattern), "/"), "/")
+	for _, s := range segments {
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			name, _, _ := strings.Cut(s[1:len(s)-1], ":")
+			sb.WriteString("By" + exportedName(name))
+			continue
+		}
+		sb.WriteString(exportedName(s))
+	}
+	if len(segments) == 1 && segments[0] == "" {
+		sb.WriteString("Root")
+	}
+	return sb.String()
+}
+
+// initialisms are written in upper case in Go names.
+var initialisms = map[string]bool{
+	"API": true, "DNS": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "TCP": true, "TLS": true, "UDP": true, "UI": true, "URI": true,
+	"URL": true, "UUID": true, "XML": true,
+}
+
+// exportedName converts a parameter or operation name, such as "X-Request-Id" or
+// "get_user", to an exported Go identifier, e.g. "XRequestID" or "GetUser".
+func exportedName(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var sb strings.Builder
+	for _, p := range parts {
+		if initialisms[strings.ToUpper(p)] {
+			sb.WriteString(strings.ToUpper(p))
+			continue
+		}
+		runes := []rune(p)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	name := sb.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// qualifiedIdent matches the package qualified type names used by reflect in the names
+// of generic types, e.g. github.com/ihezebin/openapi/examples/models.Topic.
+var qualifiedIdent = regexp.MustCompile(`([\w./~-]*\w)\.(\w+)`)
+
+// typeName returns the Go source of the type, adding the packages it uses to the imports.
+func (g *generator) typeName(t reflect.Type) (string, error) {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name(), nil
+		}
+		if err := checkImportable(t); err != nil {
+			return "", err
+		}
+		name := t.Name()
+		if i := strings.IndexByte(name, '['); i >= 0 {
+			// Generic types are named with the full package path of their type arguments.
+			args := qualifiedIdent.ReplaceAllStringFunc(name[i:], func(s string) string {
+				m := qualifiedIdent.FindStringSubmatch(s)
+				return g.imports.add(m[1]) + "." + m[2]
+			})
+			name = name[:i] + args
+		}
+		return g.imports.add(t.PkgPath()) + "." + name, nil
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		elem, err := g.typeName(t.Elem())
+		return "*" + elem, err
+	case reflect.Slice:
+		elem, err := g.typeName(t.Elem())
+		return "[]" + elem, err
+	case reflect.Array:
+		elem, err := g.typeName(t.Elem())
+		return fmt.Sprintf("[%d]%s", t.Len(), elem), err
+	case reflect.Map:
+		key, err := g.typeName(t.Key())
+		if err != nil {
+			return "", err
+		}
+		elem, err := g.typeName(t.Elem())
+		return "map[" + key + "]" + elem, err
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any", nil
+		}
+	case reflect.Struct:
+		fields := make([]string, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			ft, err := g.typeName(f.Type)
+			if err != nil {
+				return "", fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			if !f.Anonymous {
+				ft = f.Name + " " + ft
+			}
+			if f.Tag != "" {
+				ft += " " + tagLiteral(string(f.Tag))
+			}
+			fields[i] = ft
+		}
+		if len(fields) == 0 {
+			return "struct{}", nil
+		}
+		return "struct {\n" + strings.Join(fields, "\n") + "\n}", nil
+	}
+	return "", fmt.Errorf("type %s is not supported", t)
+}
+
+func checkImportable(t reflect.Type) error {
+	pkg := t.PkgPath()
+	if pkg == "main" || strings.HasSuffix(pkg, "_test") {
+		return fmt.Errorf("type %s is declared in package %s, which can't be imported", t, pkg)
+	}
+	if r := []rune(t.Name()); !unicode.IsUpper(r[0]) {
+		return fmt.Errorf("type %s is not exported", t)
+	}
+	return nil
+}
+
+func tagLiteral(tag string) string {
+	if strconv.CanBackquote(tag) {
+		return "`" + tag + "`"
+	}
+	return strconv.Quote(tag)
+}
+
+var clientTemplate = template.Must(template.New("client").Parse(`// Code generated by github.com/ihezebin/openapi/goclient. DO NOT EDIT.
+
+// Package {{ .Package }} is a client of the {{ .Title }} API.
+package {{ .Package }}
+
+import (
+{{- range .Imports }}
+	{{ if .Alias }}{{ .Alias }} {{ end }}"{{ .Path }}"
+{{- end }}
+{{- if .ThirdPartyImports }}
+{{ range .ThirdPartyImports }}
+	{{ .Alias }} "{{ .Path }}"
+{{- end }}
+{{- end }}
+)
+
+// Client of the {{ .Title }} API.
+type Client struct {
+	// BaseURL of the API, e.g. http://localhost:8080
+	BaseURL string
+	// HTTPClient sends the requests, http.DefaultClient is used if it's nil.
+	HTTPClient *http.Client
+	// Header is added to every request, e.g. to set an Authorization header.
+	Header http.Header
+}
+
+// New creates a client of the API at baseURL.
+func New(baseURL string) *Client {
+	return &Client{BaseURL: baseURL}
+}
+
+// Error is returned when the API responds with a status code that isn't a result of the method.
+type Error struct {
+	// StatusCode of the response.
+	StatusCode int
+	// Body of the response.
+	Body []byte
+	// Model is a pointer to the decoded body, if the API declares a model for the status code.
+	Model any
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", e.StatusCode, e.Body)
+}
+
+// formatParam formats the value of a parameter, using its MarshalText method if it has one.
+func formatParam(v any) string {
+	if m, ok := v.(encoding.TextMarshaler); ok {
+		if b, err := m.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// formatParams formats the values of an array parameter.
+func formatParams[T any](values []T) []string {
+	op := make([]string, len(values))
+	for i, v := range values {
+		op[i] = formatParam(v)
+	}
+	return op
+}
+
+// addObjectParam adds the properties of an object query parameter to the query using its
+// style, e.g. filter[status]=open in the deepObject style. The properties are the fields of the
+// JSON encoding of the object, and null properties aren't sent.
+func addObjectParam(query url.Values, name string, v any, style string, explode bool) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode %s query parameter err: %w", name, err)
+	}
+	var properties map[string]json.RawMessage
+	if err = json.Unmarshal(data, &properties); err != nil {
+		return fmt.Errorf("encode %s query parameter err: %w", name, err)
+	}
+	keys := make([]string, 0, len(properties))
+	for k, p := range properties {
+		if string(p) != "null" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, k := range keys {
+		value := string(properties[k])
+		var s string
+		if json.Unmarshal(properties[k], &s) == nil {
+			value = s
+		}
+		switch {
+		case style == "deepObject":
+			query.Set(name+"["+k+"]", value)
+		case explode:
+			query.Set(k, value)
+		default:
+			pairs = append(pairs, k, value)
+		}
+	}
+	if len(pairs) > 0 {
+		query.Set(name, strings.Join(pairs, ","))
+	}
+	return nil
+}
+
+// joinPathParams escapes the values of a path parameter, and joins them using its style.
+func joinPathParams(values []string, prefix, sep string) string {
+	for i, v := range values {
+		values[i] = url.PathEscape(v)
+	}
+	return prefix + strings.Join(values, sep)
+}
+
+func newError(status int, body []byte, model any) error {
+	e := &Error{StatusCode: status, Body: body}
+	if model != nil && json.Unmarshal(body, model) == nil {
+		e.Model = model
+	}
+	return e
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, body any) (status int, data []byte, err error) {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return 0, nil, fmt.Errorf("marshal request body err: %w", err)
+		}
+		r = bytes.NewReader(b)
+	}
+	u := strings.TrimSuffix(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return 0, nil, fmt.Errorf("create request err: %w", err)
+	}
+	for k, v := range c.Header {
+		req.Header[k] = append(req.Header[k], v...)
+	}
+	for k, v := range header {
+		if k == "Cookie" {
+			// Cookie parameters are sent with the cookies of the client.
+			req.Header[k] = append(req.Header[k], v...)
+			continue
+		}
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	data, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("read response body err: %w", err)
+	}
+	return resp.StatusCode, data, nil
+}
+{{ range .Methods }}{{ $result := .ResultType }}{{ if .Params }}
+// {{ .Params }} are the parameters of {{ .Name }}.
+type {{ .Params }} struct {
+{{- range .Fields }}
+	// {{ .Name }} is the {{ .Param }} {{ .In }} parameter{{ if .Doc }}: {{ .Doc }}{{ end }}.
+	{{ .Name }} {{ if .Optional }}*{{ end }}{{ .Type }}
+{{- end }}
+}
+{{ end }}
+// {{ .Name }} calls {{ .Method }} {{ .Pattern }}.
+{{- if .Summary }}
+//
+// {{ .Summary }}
+{{- end }}
+{{- if .Doc }}
+//
+// {{ .Doc }}
+{{- end }}
+{{- if .Deprecated }}
+//
+// Deprecated: the operation is deprecated.
+{{- end }}
+func (c *Client) {{ .Name }}(ctx context.Context{{ if .Params }}, params {{ .Params }}{{ end }}{{ if .RequestType }}, body {{ .RequestType }}{{ end }}) ({{ if .ResultType }}result {{ .ResultType }}, {{ end }}err error) {
+{{- if .HasQuery }}
+	query := url.Values{}
+{{- range .Fields }}{{ if eq .In "query" }}
+{{- if .Object }}
+	if err = addObjectParam(query, "{{ .Param }}", params.{{ .Name }}, "{{ .Style }}", {{ .Explode }}); err != nil {
+		return {{ if $result }}result, {{ end }}err
+	}
+{{- else if and .Array (not .Sep) }}
+	for _, v := range {{ .Value }} {
+		query.Add("{{ .Param }}", v)
+	}
+{{- else if .Array }}
+	if len(params.{{ .Name }}) > 0 {
+		query.Set("{{ .Param }}", {{ .Joined }})
+	}
+{{- else if .Optional }}
+	if params.{{ .Name }} != nil {
+		query.Set("{{ .Param }}", {{ .Value }})
+	}
+{{- else }}
+	query.Set("{{ .Param }}", {{ .Value }})
+{{- end }}
+{{- end }}{{ end }}
+{{- end }}
+{{- if .HasHeader }}
+	header := http.Header{}
+{{- range .Fields }}{{ if eq .In "header" }}
+{{- if .Array }}
+	if len(params.{{ .Name }}) > 0 {
+		header.Set("{{ .Param }}", {{ .Joined }})
+	}
+{{- else if .Optional }}
+	if params.{{ .Name }} != nil {
+		header.Set("{{ .Param }}", {{ .Value }})
+	}
+{{- else }}
+	header.Set("{{ .Param }}", {{ .Value }})
+{{- end }}
+{{- end }}{{ end }}
+{{- range .Fields }}{{ if eq .In "cookie" }}
+{{- if .Array }}
+	if len(params.{{ .Name }}) > 0 {
+		header.Add("Cookie", (&http.Cookie{Name: "{{ .Param }}", Value: {{ .Joined }}}).String())
+	}
+{{- else if .Optional }}
+	if params.{{ .Name }} != nil {
+		header.Add("Cookie", (&http.Cookie{Name: "{{ .Param }}", Value: {{ .Value }}}).String())
+	}
+{{- else }}
+	header.Add("Cookie", (&http.Cookie{Name: "{{ .Param }}", Value: {{ .Value }}}).String())
+{{- end }}
+{{- end }}{{ end }}
+{{- end }}
+	status, data, err := c.do(ctx, "{{ .Method }}", {{ .Path }}, {{ if .HasQuery }}query{{ else }}nil{{ end }}, {{ if .HasHeader }}header{{ else }}nil{{ end }}, {{ if .RequestType }}body{{ else }}nil{{ end }})
+	if err != nil {
+		return {{ if .ResultType }}result, {{ end }}err
+	}
+{{- if or .ResultType .Errors }}
+	switch status {
+{{- if .ResultType }}
+	case {{ range $i, $s := .ResultStatuses }}{{ if $i }}, {{ end }}{{ $s }}{{ end }}:
+		if err = json.Unmarshal(data, &result); err != nil {
+			return result, fmt.Errorf("decode response body err: %w", err)
+		}
+		return result, nil
+{{- end }}
+{{- range .Errors }}
+	case {{ .Status }}:
+		return {{ if $result }}result, {{ end }}newError(status, data, new({{ .Type }}))
+{{- end }}
+	}
+{{- end }}
+{{- if not .ResultType }}
+	if status >= 200 && status < 300 {
+		return nil
+	}
+{{- end }}
+	return {{ if .ResultType }}result, {{ end }}newError(status, data, {{ if .DefaultError }}new({{ .DefaultError }}){{ else }}nil{{ end }})
+}
+{{ end }}`))
@@ -0,0 +1,265 @@
+package goclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ihezebin/openapi"
+	"github.com/ihezebin/openapi/examples/models"
+	"github.com/ihezebin/openapi/goclient/tests/topics"
+)
+
+var update = flag.Bool("update", false, "update the generated client in tests/topics")
+
+const expectedClientFile = "tests/topics/client.go"
+
+func newTestAPI() *openapi.API {
+	api := openapi.NewAPI("topics")
+	api.Get("/topic/{id}").
+		HasPathParameter("id", openapi.PathParam{
+			Description: "id of the topic",
+		}).
+		HasQueryParameter("limit", openapi.QueryParam{
+			Description: "max number of messages",
+			Type:        openapi.PrimitiveTypeInteger,
+		}).
+		HasHeaderParameter("X-Request-Id", openapi.HeaderParam{
+			Required: true,
+		}).
+		HasResponseModel(http.StatusOK, openapi.ModelOf[models.Body[*models.Topic]]()).
+		HasResponseModel(http.StatusNotFound, openapi.ModelOf[models.Body[map[string]string]]()).
+		HasOperationID("getTopic").
+		HasSummary("Get one topic by id")
+	api.Post("/topic").
+		HasRequestModel(openapi.ModelOf[struct {
+			Topic models.Topic `json:"topic"`
+			Tags  []string     `json:"tags,omitempty"`
+		}]()).
+		HasResponseModel(http.StatusCreated, openapi.ModelOf[models.Topic]()).
+		HasDescription("Create a topic.").
+		HasDeprecated(true)
+	api.Delete("/topic/{id}").
+		HasPathParameter("id", openapi.PathParam{
+			Type: openapi.PrimitiveTypeInteger,
+		})
+	api.Get("/topics/{ids}").
+		HasPathParameter("ids", openapi.PathParam{Model: openapi.ModelOf[[]int](), Style: "label"}).
+		HasParams(openapi.ParamsOf[struct {
+			Limit   int               `query:"limit"`
+			Tags    []string          `query:"tags,omitempty"`
+			Sizes   []int             `query:"sizes,style=pipeDelimited,explode=false,omitempty"`
+			Since   time.Time         `query:"since,omitempty"`
+			Filter  map[string]string `query:"filter,style=deepObject,omitempty"`
+			Scope   *models.Topic     `query:"scope,explode=false,omitempty"`
+			Zones   []string          `header:"X-Zones,omitempty"`
+			Session string            `cookie:"session"`
+			Prefs   []string          `cookie:"prefs,omitempty"`
+		}]()).
+		HasQueryParameter("n", openapi.QueryOf[int64]("")).
+		HasResponseModel(http.StatusOK, openapi.ModelOf[[]models.Topic]()).
+		HasOperationID("listTopics")
+	return api
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := Generate(newTestAPI(), WithPackageName("topics"))
+	if err != nil {
+		t.Fatalf("failed to generate client: %v", err)
+	}
+	if *update {
+		if err = os.WriteFile(expectedClientFile, src, 0o644); err != nil {
+			t.Fatalf("failed to write client: %v", err)
+		}
+	}
+	expected, err := os.ReadFile(expectedClientFile)
+	if err != nil {
+		t.Fatalf("failed to read expected client: %v", err)
+	}
+	if diff := cmp.Diff(string(expected), string(src)); diff != "" {
+		t.Errorf("generated client doesn't match %s, run go test -update to regenerate:\n%s", expectedClientFile, diff)
+	}
+}
+
+func TestGeneratedClient(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/topic/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/topic/a b":
+			if r.Header.Get("X-Request-Id") != "req-1" || r.URL.Query().Get("limit") != "5" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(models.Body[*models.Topic]{Data: &models.Topic{Topic: "a b"}})
+		case r.Method == http.MethodDelete && r.URL.Path == "/topic/1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(models.Body[map[string]string]{Data: map[string]string{"path": r.URL.Path}})
+		}
+	})
+	mux.HandleFunc("/topic", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Topic models.Topic `json:"topic"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(body.Topic)
+	})
+	mux.HandleFunc("/topics/", func(w http.ResponseWriter, r *http.Request) {
+		var cookies []string
+		for _, c := range r.Cookies() {
+			cookies = append(cookies, c.Name+"="+c.Value)
+		}
+		actual := fmt.Sprintf("%s %s %v %s %v", r.URL.EscapedPath(), r.URL.RawQuery, r.URL.Query()["tags"], r.Header.Get("X-Zones"), cookies)
+		expected := "/topics/.1,2 filter%5Bstatus%5D=open&limit=10&n=3&scope=namespace%2Cns%2Cprivate%2Cfalse%2Ctopic%2Ct&since=2024-01-02T03%3A04%3A05Z&sizes=1%7C2&tags=a&tags=b [a b] eu,us [theme=dark prefs=x,y session=s1]"
+		if actual != expected {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(actual)
+			return
+		}
+		json.NewEncoder(w).Encode([]models.Topic{{Topic: "a"}})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx := context.Background()
+	client := topics.New(server.URL)
+
+	limit := 5
+	got, err := client.GetTopic(ctx, topics.GetTopicParams{ID: "a b", Limit: &limit, XRequestID: "req-1"})
+	if err != nil {
+		t.Fatalf("unexpected error getting topic: %v", err)
+	}
+	if got.Data == nil || got.Data.Topic != "a b" {
+		t.Errorf("unexpected topic: %+v", got)
+	}
+
+	created, err := client.PostTopic(ctx, struct {
+		Topic models.Topic `json:"topic"`
+		Tags  []string     `json:"tags,omitempty"`
+	}{Topic: models.Topic{Namespace: "ns"}})
+	if err != nil {
+		t.Fatalf("unexpected error creating topic: %v", err)
+	}
+	if created.Namespace != "ns" {
+		t.Errorf("unexpected created topic: %+v", created)
+	}
+
+	if err = client.DeleteTopicByID(ctx, topics.DeleteTopicByIDParams{ID: 1}); err != nil {
+		t.Errorf("unexpected error deleting topic: %v", err)
+	}
+
+	client.Header = http.Header{"Cookie": {"theme=dark"}}
+	n := int64(3)
+	listed, err := client.ListTopics(ctx, topics.ListTopicsParams{
+		Ids:     []int{1, 2},
+		Limit:   10,
+		N:       &n,
+		Tags:    []string{"a", "b"},
+		Sizes:   []int{1, 2},
+		Since:   ptr(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+		XZones:  []string{"eu", "us"},
+		Session: "s1",
+		Prefs:   []string{"x", "y"},
+		Filter:  map[string]string{"status": "open"},
+		Scope:   &models.Topic{Namespace: "ns", Topic: "t"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error listing topics: %v", err)
+	}
+	if len(listed) != 1 {
+		t.Errorf("unexpected topics: %+v", listed)
+	}
+
+	_, err = client.GetTopic(ctx, topics.GetTopicParams{ID: "missing", XRequestID: "req-2"})
+	var apiErr *topics.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *topics.Error, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", apiErr.StatusCode)
+	}
+	model, ok := apiErr.Model.(*models.Body[map[string]string])
+	if !ok || model.Data["path"] != "/topic/missing" {
+		t.Errorf("expected the 404 model to be decoded, got %#v", apiErr.Model)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	type unexported struct{}
+	tests := []struct {
+		name     string
+		api      func() *openapi.API
+		expected string
+	}{
+		{
+			name: "path parameters that aren't in the pattern",
+			api: func() *openapi.API {
+				api := openapi.NewAPI("test")
+				api.Get("/topic/{id}").HasPathParameter("topicID", openapi.PathParam{})
+				return api
+			},
+			expected: `GET /topic/{id}: path parameter "topicID" is not in the pattern`,
+		},
+		{
+			name: "duplicate method names",
+			api: func() *openapi.API {
+				api := openapi.NewAPI("test")
+				api.Get("/a").HasOperationID("get")
+				api.Get("/b").HasOperationID("get")
+				return api
+			},
+			expected: `GET /b: method name "Get" is already used by GET /a, set a unique operation ID`,
+		},
+		{
+			name: "models that can't be imported",
+			api: func() *openapi.API {
+				api := openapi.NewAPI("test")
+				api.Get("/a").HasResponseModel(http.StatusOK, openapi.ModelOf[unexported]())
+				return api
+			},
+			expected: "is not exported",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Generate(test.api())
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("expected error containing %q, got %q", test.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	tests := map[string]string{
+		"getTopic":     "GetTopic",
+		"X-Request-Id": "XRequestID",
+		"user_id":      "UserID",
+		"2fa":          "X2fa",
+		"api.url":      "APIURL",
+	}
+	for input, expected := range tests {
+		if actual := exportedName(input); actual != expected {
+			t.Errorf("exportedName(%q): expected %q, got %q", input, expected, actual)
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
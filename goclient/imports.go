@@ -0,0 +1,130 @@
+package goclient
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// clientImports are used by the generated client code.
+var clientImports = []string{
+	"bytes",
+	"context",
+	"encoding",
+	"encoding/json",
+	"fmt",
+	"io",
+	"net/http",
+	"net/url",
+	"sort",
+	"strings",
+}
+
+// reservedNames are the local names used in the generated code, which can't be used
+// as the names of imported packages.
+var reservedNames = []string{"body", "c", "ctx", "data", "e", "err", "header", "hc", "params", "query", "req", "resp", "result", "status", "u", "v"}
+
+type importSpec struct {
+	// Alias is set for packages outside the standard library, since their name may differ
+	// from the last element of the path.
+	Alias string
+	Path  string
+}
+
+// imports assigns a unique name to each package used by the generated code.
+type imports struct {
+	pathToName map[string]string
+	names      map[string]bool
+}
+
+func newImports() *imports {
+	im := &imports{
+		pathToName: map[string]string{},
+		names:      map[string]bool{},
+	}
+	for _, name := range reservedNames {
+		im.names[name] = true
+	}
+	for _, path := range clientImports {
+		im.add(path)
+	}
+	return im
+}
+
+// add the package to the imports, returning the name to use for it.
+func (im *imports) add(path string) string {
+	if name, ok := im.pathToName[path]; ok {
+		return name
+	}
+	base := packageName(path)
+	name := base
+	for i := 2; im.names[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	im.pathToName[path] = name
+	im.names[name] = true
+	return name
+}
+
+// list the imports, in the standard library and other groups.
+func (im *imports) list() (std, other []importSpec) {
+	for path, name := range im.pathToName {
+		if isStandardLibrary(path) {
+			spec := importSpec{Path: path}
+			if name != lastElement(path) {
+				spec.Alias = name
+			}
+			std = append(std, spec)
+			continue
+		}
+		other = append(other, importSpec{Alias: name, Path: path})
+	}
+	sortImports(std)
+	sortImports(other)
+	return std, other
+}
+
+func sortImports(specs []importSpec) {
+	sort.Slice(specs, func(i, j int) bool {
+		return specs[i].Path < specs[j].Path
+	})
+}
+
+// packageName guesses the name of a package from its path, skipping major version
+// suffixes, e.g. github.com/a/b/v2 is named b.
+func packageName(path string) string {
+	elems := strings.Split(path, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && isMajorVersion(name) {
+		name = elems[len(elems)-2]
+	}
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, strings.Split(name, ".")[0])
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "pkg" + name
+	}
+	return name
+}
+
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
+
+func lastElement(path string) string {
+	return path[strings.LastIndexByte(path, '/')+1:]
+}
+
+// isStandardLibrary returns true if the first element of the path doesn't contain a dot.
+func isStandardLibrary(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
@@ -0,0 +1,169 @@
+package openapi
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// GroupOpts configures the documentation shared by the routes of a Group.
+type GroupOpts func(*Group)
+
+// WithGroupTags adds the tags to each route in the group.
+func WithGroupTags(tags ...string) GroupOpts {
+	return func(g *Group) {
+		g.tags = append(g.tags, tags...)
+	}
+}
+
+// WithGroupParams adds the parameters to each route in the group, e.g. ParamsOf[AuthHeaders]().
+func WithGroupParams(p Params) GroupOpts {
+	return func(g *Group) {
+		mergeMap(g.params.Path, p.Path)
+		mergeMap(g.params.Query, p.Query)
+		mergeMap(g.params.Header, p.Header)
+		mergeMap(g.params.Cookie, p.Cookie)
+	}
+}
+
+// WithGroupHeaderParameter adds the header parameter to each route in the group.
+func WithGroupHeaderParameter(name string, h HeaderParam) GroupOpts {
+	return func(g *Group) {
+		g.params.Header[name] = h
+	}
+}
+
+// WithGroupResponseModel adds the response to each route in the group, e.g. a shared error response.
+func WithGroupResponseModel(status int, response Model) GroupOpts {
+	return func(g *Group) {
+		g.responses[status] = response
+	}
+}
+
+// WithGroupSecurity adds the security requirement to each route in the group.
+func WithGroupSecurity(requirement openapi3.SecurityRequirement) GroupOpts {
+	return func(g *Group) {
+		g.security = append(g.security, requirement)
+	}
+}
+
+// Group is a set of routes that share a path prefix, and documentation such as tags,
+// parameters, responses and security requirements.
+//
+// The shared documentation is added to each route when it's created. Parameters and responses
+// that are declared on the route itself take precedence over those of the group.
+type Group struct {
+	api       *API
+	prefix    string
+	tags      []string
+	params    Params
+	responses map[int]Model
+	security  openapi3.SecurityRequirements
+}
+
+// Group creates a group of routes under the path prefix.
+// Example:
+//
+//	admin := api.Group("/v1/admin",
+//		openapi.WithGroupTags("Admin"),
+//		openapi.WithGroupHeaderParameter("Authorization", openapi.HeaderParam{Required: true}),
+//		openapi.WithGroupResponseModel(http.StatusUnauthorized, openapi.ModelOf[openapi.ProblemDetails]()))
+//	admin.Get("/users").HasResponseModel(http.StatusOK, openapi.ModelOf[[]User]())
+func (api *API) Group(prefix string, opts ...GroupOpts) *Group {
+	g := &Group{
+		api:    api,
+		prefix: prefix,
+		params: Params{
+			Path:   make(map[string]PathParam),
+			Query:  make(map[string]QueryParam),
+			Header: make(map[string]HeaderParam),
+			Cookie: make(map[string]CookieParam),
+		},
+		responses: make(map[int]Model),
+	}
+	for _, o := range opts {
+		o(g)
+	}
+	return g
+}
+
+// Group creates a nested group, which adds its prefix to the prefix of the parent group, and
+// shares the documentation of the parent group.
+func (g *Group) Group(prefix string, opts ...GroupOpts) *Group {
+	child := g.api.Group(g.prefix+prefix,
+		WithGroupTags(g.tags...),
+		WithGroupParams(g.params),
+	)
+	mergeMap(child.responses, g.responses)
+	child.security = slices.Clone(g.security)
+	for _, o := range opts {
+		o(child)
+	}
+	return child
+}
+
+// Route upserts a route under the prefix of the group, with the documentation of the group.
+func (g *Group) Route(method, pattern string) *Route {
+	route := g.api.Route(method, g.prefix+pattern)
+	return route.update(func() {
+		for _, tag := range g.tags {
+			if !slices.Contains(route.Tags, tag) {
+				route.Tags = append(route.Tags, tag)
+			}
+		}
+		mergeMap(route.Params.Path, g.params.Path)
+		mergeMap(route.Params.Query, g.params.Query)
+		mergeMap(route.Params.Header, g.params.Header)
+		mergeMap(route.Params.Cookie, g.params.Cookie)
+		mergeMap(route.Models.Responses, g.responses)
+		if len(route.Security) == 0 {
+			route.Security = slices.Clone(g.security)
+		}
+	})
+}
+
+// Get defines a GET request route for the given pattern, under the prefix of the group.
+func (g *Group) Get(pattern string) *Route {
+	return g.Route(http.MethodGet, pattern)
+}
+
+// Head defines a HEAD request route for the given pattern, under the prefix of the group.
+func (g *Group) Head(pattern string) *Route {
+	return g.Route(http.MethodHead, pattern)
+}
+
+// Post defines a POST request route for the given pattern, under the prefix of the group.
+func (g *Group) Post(pattern string) *Route {
+	return g.Route(http.MethodPost, pattern)
+}
+
+// Put defines a PUT request route for the given pattern, under the prefix of the group.
+func (g *Group) Put(pattern string) *Route {
+	return g.Route(http.MethodPut, pattern)
+}
+
+// Patch defines a PATCH request route for the given pattern, under the prefix of the group.
+func (g *Group) Patch(pattern string) *Route {
+	return g.Route(http.MethodPatch, pattern)
+}
+
+// Delete defines a DELETE request route for the given pattern, under the prefix of the group.
+func (g *Group) Delete(pattern string) *Route {
+	return g.Route(http.MethodDelete, pattern)
+}
+
+// Connect defines a CONNECT request route for the given pattern, under the prefix of the group.
+func (g *Group) Connect(pattern string) *Route {
+	return g.Route(http.MethodConnect, pattern)
+}
+
+// Options defines an OPTIONS request route for the given pattern, under the prefix of the group.
+func (g *Group) Options(pattern string) *Route {
+	return g.Route(http.MethodOptions, pattern)
+}
+
+// Trace defines a TRACE request route for the given pattern, under the prefix of the group.
+func (g *Group) Trace(pattern string) *Route {
+	return g.Route(http.MethodTrace, pattern)
+}
@@ -0,0 +1,84 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGroup(t *testing.T) {
+	api := NewAPI("admin", WithSecurityScheme("bearerAuth", openapi3.NewJWTSecurityScheme()))
+	admin := api.Group("/v1/admin",
+		WithGroupTags("Admin"),
+		WithGroupHeaderParameter("X-Tenant", HeaderParam{Description: "The tenant.", Required: true}),
+		WithGroupResponseModel(http.StatusUnauthorized, ModelOf[ProblemDetails]()),
+		WithGroupSecurity(openapi3.SecurityRequirement{"bearerAuth": []string{}}),
+	)
+	admin.Get("/users").
+		HasResponseModel(http.StatusOK, ModelOf[[]User]())
+	admin.Delete("/users/{id}").
+		HasTags([]string{"Users"}).
+		HasHeaderParameter("X-Tenant", HeaderParam{Description: "The tenant of the user."}).
+		HasResponseModel(http.StatusNoContent, ModelOf[string]())
+	audit := admin.Group("/audit", WithGroupTags("Audit"))
+	audit.Get("/events").
+		HasResponseModel(http.StatusOK, ModelOf[[]string]())
+	api.Get("/health").HasResponseModel(http.StatusOK, ModelOf[string]())
+
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	if spec.Components.SecuritySchemes["bearerAuth"] == nil {
+		t.Error("expected the security scheme to be documented")
+	}
+
+	tests := []struct {
+		path           string
+		method         string
+		expectedTags   []string
+		expectedTenant string
+		expectedSecure bool
+	}{
+		{path: "/v1/admin/users", method: http.MethodGet, expectedTags: []string{"Admin"}, expectedTenant: "The tenant.", expectedSecure: true},
+		{path: "/v1/admin/users/{id}", method: http.MethodDelete, expectedTags: []string{"Admin", "Users"}, expectedTenant: "The tenant of the user.", expectedSecure: true},
+		{path: "/v1/admin/audit/events", method: http.MethodGet, expectedTags: []string{"Admin", "Audit"}, expectedTenant: "The tenant.", expectedSecure: true},
+		{path: "/health", method: http.MethodGet},
+	}
+	for _, test := range tests {
+		t.Run(test.method+" "+test.path, func(t *testing.T) {
+			item := spec.Paths.Find(test.path)
+			if item == nil {
+				t.Fatalf("expected %s to be documented", test.path)
+			}
+			op := item.GetOperation(test.method)
+			if diff := cmp.Diff(test.expectedTags, op.Tags); diff != "" {
+				t.Errorf("unexpected tags: %s", diff)
+			}
+			tenant := op.Parameters.GetByInAndName("header", "X-Tenant")
+			if test.expectedTenant == "" {
+				if tenant != nil {
+					t.Error("expected the route not to have the X-Tenant header")
+				}
+				if op.Responses.Status(http.StatusUnauthorized) != nil {
+					t.Error("expected the route not to have the 401 response")
+				}
+				if op.Security != nil {
+					t.Error("expected the route not to have security requirements")
+				}
+				return
+			}
+			if tenant == nil || tenant.Description != test.expectedTenant {
+				t.Errorf("expected the X-Tenant header to be described as %q, got %+v", test.expectedTenant, tenant)
+			}
+			if op.Responses.Status(http.StatusUnauthorized) == nil {
+				t.Error("expected the group's 401 response")
+			}
+			if op.Security == nil || len(*op.Security) != 1 {
+				t.Errorf("expected the group's security requirement, got %v", op.Security)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Mux registers HTTP handlers for patterns, e.g. *http.ServeMux.
+type Mux interface {
+	Handle(pattern string, handler http.Handler)
+}
+
+// muxHandle returns the function that registers handlers on the mux, which is a Mux, or a
+// *ServeMux. Handlers are registered on the embedded http.ServeMux of a *ServeMux, since the
+// caller documents the route. It panics if the mux can't register handlers.
+func muxHandle(mux http.Handler) func(pattern string, handler http.Handler) {
+	switch m := mux.(type) {
+	case *ServeMux:
+		return m.ServeMux.Handle
+	case Mux:
+		return m.Handle
+	}
+	panic(fmt.Sprintf("openapi: %T can't register handlers, since it isn't a Mux or a *ServeMux", mux))
+}
+
+// requireMethod panics if the pattern doesn't have a method, since the route is documented,
+// and validated, for that method alone.
+func requireMethod(pattern string) {
+	if len(strings.Fields(pattern)) < 2 {
+		panic(fmt.Sprintf("openapi: the pattern %q must have a method, e.g. \"GET %s\"", pattern, pattern))
+	}
+}
+
+// HandleOpts configures a handler registered with Handle.
+type HandleOpts func(*handleConfig)
+
+type handleConfig struct {
+	status   int
+	validate bool
+}
+
+// WithSuccessStatus sets the status code of successful responses, which defaults to 200 OK.
+func WithSuccessStatus(status int) HandleOpts {
+	return func(c *handleConfig) {
+		c.status = status
+	}
+}
+
+// WithoutRequestValidation disables the validation of requests against the API specification.
+// Requests are still decoded, and validated by the Validate method of the request type, if it has one.
+func WithoutRequestValidation() HandleOpts {
+	return func(c *handleConfig) {
+		c.validate = false
+	}
+}
+
+// Validator is implemented by request types that check their own values.
+// If Validate returns an error, the request is rejected with a 400 Bad Request response.
+type Validator interface {
+	Validate() error
+}
+
+// Handle registers a typed handler on the mux and documents the route in the API in one step.
+//
+// The mux is a Mux, such as *http.ServeMux, or a *ServeMux, and Handle panics if it's neither.
+// The pattern uses the syntax of http.ServeMux, e.g. "POST /users/{id}", and must have a method,
+// since the route is documented, and validated, for that method alone. Handle panics if the
+// pattern doesn't have a method.
+//
+// Requests are validated against the API specification, except for routes with a multi-segment
+// wildcard, such as "GET /files/{path...}", since OpenAPI path parameters match a single segment.
+// If the specification isn't valid, e.g. a model of another route can't be documented, the error
+// is logged, and requests fail with a 500 Internal Server Error until the routes are fixed; check
+// the result of api.Spec at startup to fail early. The JSON body is decoded into Req
+// for methods that have a body. Fields of Req with path, query, header or cookie tags are
+// documented and bound as parameters, see ParamsOf and BindParams, and should be tagged with
+// json:"-" if the request also has a body. The Resp returned by f is encoded as JSON. If f returns a
+// *ProblemDetails error, it's written to the client, other errors become a 500 Internal Server
+// Error, without exposing the error message. Responses with the 204 No Content or 304 Not
+// Modified status have no body.
+//
+// The route is documented with ModelOf[Req] as the request model, ModelOf[Resp] as the response
+// model, unless the response has no body, and ProblemDetails for the 400 and 500 error responses.
+// Further documentation can be added to the returned route, e.g. additional error responses:
+//
+//	openapi.Handle(mux, api, "GET /users/{id}", getUser).
+//		HasResponseModel(http.StatusNotFound, openapi.ModelOf[openapi.ProblemDetails]())
+func Handle[Req, Resp any](mux http.Handler, api *API, pattern string, f func(ctx context.Context, req Req) (Resp, error), opts ...HandleOpts) *Route {
+	config := &handleConfig{
+		status:   http.StatusOK,
+		validate: true,
+	}
+	for _, o := range opts {
+		o(config)
+	}
+	requireMethod(pattern)
+	handle := muxHandle(mux)
+	// OpenAPI path parameters can't contain a slash, so the spec can't match the route.
+	if strings.Contains(pattern, "...}") {
+		config.validate = false
+	}
+	method, path := parsePattern(pattern)
+	route := api.Route(method, path)
+	reqType := reflect.TypeFor[Req]()
+	hasBody := methodHasBody(method) && hasBodyFields(reqType)
+	if hasBody {
+		route.HasRequestModel(ModelOf[Req]())
+	}
+	hasParams := reqType.Kind() == reflect.Struct && len(taggedFields(reqType)) > 0
+	if hasParams {
+		route.HasParams(ParamsOf[Req]())
+	}
+	// 204 No Content and 304 Not Modified responses can't have a body.
+	noContent := config.status == http.StatusNoContent || config.status == http.StatusNotModified
+	respModel := ModelOf[Resp]()
+	if noContent {
+		respModel = NoSchema()
+	}
+	route.HasResponseModel(config.status, respModel).
+		HasResponseModel(http.StatusBadRequest, ModelOf[ProblemDetails]()).
+		HasResponseModel(http.StatusInternalServerError, ModelOf[ProblemDetails]())
+
+	handle(pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if config.validate {
+			v, err := api.handlerValidator()
+			if err != nil {
+				writeHandlerProblem(w, r, NewProblemDetails(http.StatusInternalServerError, "The API specification is not valid."))
+				return
+			}
+			if p := v.Validate(r); p != nil {
+				writeHandlerProblem(w, r, p)
+				return
+			}
+		}
+		var req Req
+		if hasBody {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+				writeHandlerProblem(w, r, NewProblemDetails(http.StatusBadRequest, fmt.Sprintf("The request body is not valid JSON: %v", err)))
+				return
+			}
+		}
+		if hasParams {
+			if err := bindParams(r, reflect.ValueOf(&req).Elem()); err != nil {
+				var p *ProblemDetails
+				if !errors.As(err, &p) {
+					p = NewProblemDetails(http.StatusBadRequest, err.Error())
+				}
+				writeHandlerProblem(w, r, p)
+				return
+			}
+		}
+		if v, ok := any(&req).(Validator); ok {
+			if err := v.Validate(); err != nil {
+				writeHandlerProblem(w, r, NewProblemDetails(http.StatusBadRequest, err.Error()))
+				return
+			}
+		}
+		resp, err := f(r.Context(), req)
+		if err != nil {
+			var p *ProblemDetails
+			if !errors.As(err, &p) {
+				p = NewProblemDetails(http.StatusInternalServerError, "")
+			}
+			writeHandlerProblem(w, r, p)
+			return
+		}
+		if noContent {
+			w.WriteHeader(config.status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(config.status)
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			log.Printf("openapi: failed to encode the response of %s %s: %v", r.Method, r.URL.Path, err)
+		}
+	}))
+	return route
+}
+
+func writeHandlerProblem(w http.ResponseWriter, r *http.Request, p *ProblemDetails) {
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+	WriteProblem(w, p)
+}
+
+// handlerValidator returns the request validator shared by the handlers of the API, creating it
+// from the specification when it's first used, and again when the routes change. If the
+// specification isn't valid, the error is logged once, and returned until the routes change.
+func (api *API) handlerValidator() (*RequestValidator, error) {
+	api.validatorMu.Lock()
+	defer api.validatorMu.Unlock()
+	version := api.currentVersion()
+	if (api.validator == nil && api.validatorErr == nil) || api.validatorVersion != version {
+		api.validator, api.validatorErr = NewRequestValidator(api)
+		api.validatorVersion = version
+		if api.validatorErr != nil {
+			log.Printf("openapi: requests to handlers of %q can't be validated: %v", api.Name, api.validatorErr)
+		}
+	}
+	return api.validator, api.validatorErr
+}
+
+// parsePattern splits a http.ServeMux pattern, such as "POST example.com/users/{id}", into
+// the method and path that are documented. The method is empty for patterns without a method,
+// which match all methods, so can't be documented as a single route. Hosts are removed,
+// multi-segment wildcards such as {path...} become {path}, and the end of path marker {$}
+// is removed.
+func parsePattern(pattern string) (method, path string) {
+	fields := strings.Fields(pattern)
+	switch len(fields) {
+	case 0:
+		return "", "/"
+	case 1:
+		path = fields[0]
+	default:
+		method, path = fields[0], fields[1]
+	}
+	if i := strings.IndexByte(path, '/'); i > 0 {
+		path = path[i:]
+	}
+	path = strings.ReplaceAll(path, "{$}", "")
+	path = strings.ReplaceAll(path, "...}", "}")
+	if path == "" {
+		path = "/"
+	}
+	return method, path
+}
+
+func methodHasBody(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions, http.MethodTrace:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,282 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+type CreateUserRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func (r CreateUserRequest) Validate() error {
+	if !strings.Contains(r.Email, "@") {
+		return errors.New("email must contain @")
+	}
+	return nil
+}
+
+type UserResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func newHandleTestMux(t *testing.T) (*API, *http.ServeMux) {
+	api := NewAPI("users")
+	mux := http.NewServeMux()
+	Handle(mux, api, "POST /users", func(ctx context.Context, req CreateUserRequest) (UserResponse, error) {
+		if req.Name == "taken" {
+			return UserResponse{}, NewProblemDetails(http.StatusConflict, "the name is taken")
+		}
+		if req.Name == "fail" {
+			return UserResponse{}, errors.New("database is down")
+		}
+		return UserResponse{ID: "1", Name: req.Name}, nil
+	}, WithSuccessStatus(http.StatusCreated)).
+		HasResponseModel(http.StatusConflict, ModelOf[ProblemDetails]())
+	Handle(mux, api, "GET /users/{id}", func(ctx context.Context, req struct{}) (UserResponse, error) {
+		return UserResponse{ID: "1"}, nil
+	})
+	return api, mux
+}
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "valid requests are decoded, and the response encoded",
+			method:         http.MethodPost,
+			path:           "/users",
+			body:           `{"name":"alice","email":"alice@example.com"}`,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":"1","name":"alice"}`,
+		},
+		{
+			name:           "requests that don't match the schema are rejected",
+			method:         http.MethodPost,
+			path:           "/users",
+			body:           `{"name":1,"email":"alice@example.com"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"errors":[{"in":"body","pointer":"/name"`,
+		},
+		{
+			name:           "the Validate method of the request is used",
+			method:         http.MethodPost,
+			path:           "/users",
+			body:           `{"name":"alice","email":"alice"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"detail":"email must contain @"`,
+		},
+		{
+			name:           "problem details returned by the handler are written",
+			method:         http.MethodPost,
+			path:           "/users",
+			body:           `{"name":"taken","email":"alice@example.com"}`,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"detail":"the name is taken"`,
+		},
+		{
+			name:           "other errors are not exposed",
+			method:         http.MethodPost,
+			path:           "/users",
+			body:           `{"name":"fail","email":"alice@example.com"}`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"status":500`,
+		},
+		{
+			name:           "requests without a body",
+			method:         http.MethodGet,
+			path:           "/users/1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":"1","name":""}`,
+		},
+	}
+	_, mux := newHandleTestMux(t)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+			if w.Code != test.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", test.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), test.expectedBody) {
+				t.Errorf("expected body to contain %s, got %s", test.expectedBody, w.Body.String())
+			}
+			if strings.Contains(w.Body.String(), "database is down") {
+				t.Errorf("error message was exposed: %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleDocumentsRoutes(t *testing.T) {
+	api, _ := newHandleTestMux(t)
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	post := spec.Paths.Find("/users").Post
+	if post == nil {
+		t.Fatal("expected POST /users to be documented")
+	}
+	if post.RequestBody == nil {
+		t.Error("expected POST /users to have a request body")
+	}
+	for _, status := range []int{http.StatusCreated, http.StatusBadRequest, http.StatusConflict, http.StatusInternalServerError} {
+		if post.Responses.Status(status) == nil {
+			t.Errorf("expected POST /users to document status %d", status)
+		}
+	}
+	get := spec.Paths.Find("/users/{id}").Get
+	if get == nil {
+		t.Fatal("expected GET /users/{id} to be documented")
+	}
+	if get.RequestBody != nil {
+		t.Error("expected GET /users/{id} not to have a request body")
+	}
+	if get.Parameters.GetByInAndName("path", "id") == nil {
+		t.Error("expected the id path parameter to be documented")
+	}
+	data, _ := json.Marshal(get.Responses.Status(http.StatusOK))
+	if !strings.Contains(string(data), "UserResponse") {
+		t.Errorf("expected the 200 response to use UserResponse, got %s", data)
+	}
+}
+
+type GetUserRequest struct {
+	ID     string   `path:"id"`
+	Fields []string `query:"fields,omitempty"`
+}
+
+func TestHandleBindsParams(t *testing.T) {
+	api := NewAPI("users")
+	mux := http.NewServeMux()
+	Handle(mux, api, "GET /users/{id}", func(ctx context.Context, req GetUserRequest) (GetUserRequest, error) {
+		return req, nil
+	})
+	r := httptest.NewRequest(http.MethodGet, "/users/123?fields=name&fields=email", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	expected := `{"ID":"123","Fields":["name","email"]}`
+	if actual := strings.TrimSpace(w.Body.String()); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	if spec.Paths.Find("/users/{id}").Get.Parameters.GetByInAndName("query", "fields") == nil {
+		t.Error("expected the fields query parameter to be documented")
+	}
+}
+
+type GetFileRequest struct {
+	Path string `path:"path"`
+}
+
+func TestHandleMultiSegmentWildcards(t *testing.T) {
+	api := NewAPI("files")
+	mux := http.NewServeMux()
+	Handle(mux, api, "GET /files/{path...}", func(ctx context.Context, req GetFileRequest) (GetFileRequest, error) {
+		return req, nil
+	})
+	for _, path := range []string{"a", "a/b.txt"} {
+		r := httptest.NewRequest(http.MethodGet, "/files/"+path, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", path, w.Code, w.Body.String())
+		}
+		expected := `{"Path":"` + path + `"}`
+		if actual := strings.TrimSpace(w.Body.String()); actual != expected {
+			t.Errorf("expected %s, got %s", expected, actual)
+		}
+	}
+}
+
+func TestHandleRequiresMethod(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a pattern without a method")
+		}
+	}()
+	Handle(http.NewServeMux(), NewAPI("users"), "/users", func(ctx context.Context, req struct{}) (UserResponse, error) {
+		return UserResponse{}, nil
+	})
+}
+
+func TestHandleNoContent(t *testing.T) {
+	api := NewAPI("users")
+	mux := http.NewServeMux()
+	Handle(mux, api, "DELETE /users/{id}", func(ctx context.Context, req struct{}) (struct{}, error) {
+		return struct{}{}, nil
+	}, WithSuccessStatus(http.StatusNoContent))
+	r := httptest.NewRequest(http.MethodDelete, "/users/1", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Body.Len() != 0 || w.Header().Get("Content-Type") != "" {
+		t.Errorf("expected no body, got %q with content type %q", w.Body.String(), w.Header().Get("Content-Type"))
+	}
+
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	response := spec.Paths.Find("/users/{id}").Delete.Responses.Status(http.StatusNoContent)
+	if response == nil || len(response.Value.Content) != 0 {
+		t.Errorf("expected the 204 response to be documented without content, got %+v", response)
+	}
+	v, err := NewResponseValidator(api, WithResponseViolationHandler(func(r *http.Request, v *ResponseViolation) {
+		t.Errorf("unexpected violation: %s", v)
+	}))
+	if err != nil {
+		t.Fatalf("failed to create response validator: %v", err)
+	}
+	v.Middleware(mux).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/users/1", nil))
+}
+
+func TestHandleInvalidSpec(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	api, mux := newHandleTestMux(t)
+	api.Get("/broken").HasResponseModel(http.StatusOK, ModelOf[chan int]())
+	for i := 0; i < 2; i++ {
+		r := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+	if n := strings.Count(logs.String(), "can't be validated"); n != 1 {
+		t.Errorf("expected the error to be logged once, got %d times: %s", n, logs.String())
+	}
+	if !strings.Contains(logs.String(), "GET /broken") {
+		t.Errorf("expected the log to include the invalid route, got %s", logs.String())
+	}
+}
@@ -0,0 +1,114 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io"
+	"math/big"
+	"mime/multipart"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// defaultKnownTypes are the types of the standard library that have a schema that can't be
+// created from their fields.
+//
+// The sql.Null types, url.URL and mail.Address aren't known types, since encoding/json encodes
+// their fields, e.g. {"String":"a","Valid":true}, so they're documented from their fields. APIs
+// that encode them as the value they hold, with a custom marshaller, can document them with
+// RegisterKnownType, e.g. with openapi3.NewStringSchema().WithNullable() for sql.NullString.
+var defaultKnownTypes = map[reflect.Type]openapi3.Schema{
+	reflect.TypeOf(time.Time{}):  *openapi3.NewDateTimeSchema(),
+	reflect.TypeOf(&time.Time{}): *openapi3.NewDateTimeSchema().WithNullable(),
+	// Durations are encoded as a number of nanoseconds.
+	reflect.TypeFor[time.Duration](): *openapi3.NewInt64Schema(),
+	// Raw JSON can be any value.
+	reflect.TypeFor[json.RawMessage](): {},
+	reflect.TypeFor[json.Number]():     *openapi3.NewFloat64Schema(),
+	reflect.TypeFor[net.IP]():          *openapi3.NewStringSchema(),
+	reflect.TypeFor[netip.Addr]():      *openapi3.NewStringSchema(),
+	reflect.TypeFor[netip.Prefix]():    *openapi3.NewStringSchema(),
+	// The user of a url.URL is encoded as an empty object, since it has no exported fields, and
+	// is usually nil.
+	reflect.TypeFor[*url.Userinfo](): *openapi3.NewObjectSchema().WithNullable(),
+	// big.Int is encoded as a number, and big.Float as a string, to keep its precision.
+	reflect.TypeFor[big.Int]():   *openapi3.NewIntegerSchema(),
+	reflect.TypeFor[big.Float](): *openapi3.NewStringSchema(),
+	// Files are binary data.
+	reflect.TypeFor[io.Reader]():             *newBinarySchema(),
+	reflect.TypeFor[io.ReadCloser]():         *newBinarySchema(),
+	reflect.TypeFor[*os.File]():              *newBinarySchema(),
+	reflect.TypeFor[multipart.File]():        *newBinarySchema(),
+	reflect.TypeFor[*multipart.FileHeader](): *newBinarySchema(),
+}
+
+// KnownTypeMatcher returns the schema of a type that isn't in KnownTypes, and true, if it matches
+// the type, e.g. because the type implements an interface. Matchers are added to an API with
+// WithKnownTypeMatcher.
+type KnownTypeMatcher func(t reflect.Type) (schema openapi3.Schema, ok bool)
+
+// defaultKnownTypeMatchers match types by their shape.
+var defaultKnownTypeMatchers = []KnownTypeMatcher{
+	MatchUUID,
+}
+
+// MatchUUID matches UUID types, such as github.com/google/uuid.UUID, which are 16 byte arrays
+// that marshal themselves as text, and documents them as strings with the uuid format.
+func MatchUUID(t reflect.Type) (schema openapi3.Schema, ok bool) {
+	if t.Kind() != reflect.Array || t.Len() != 16 || t.Elem().Kind() != reflect.Uint8 || !implements(t, textMarshalerType) {
+		return schema, false
+	}
+	return *openapi3.NewUUIDSchema(), true
+}
+
+// WithKnownTypeMatcher adds a matcher of known types to the API. Matchers are used in the order
+// they're added, after the default matchers, such as MatchUUID.
+// Example:
+//
+//	openapi.WithKnownTypeMatcher(func(t reflect.Type) (openapi3.Schema, bool) {
+//		if t.Implements(reflect.TypeFor[fmt.Stringer]()) {
+//			return *openapi3.NewStringSchema(), true
+//		}
+//		return openapi3.Schema{}, false
+//	})
+func WithKnownTypeMatcher(m KnownTypeMatcher) APIOpts {
+	return func(api *API) {
+		api.KnownTypeMatchers = append(api.KnownTypeMatchers, m)
+	}
+}
+
+// RegisterKnownType documents T with the schema, instead of creating the schema from the fields
+// of T, e.g. for types that are encoded by a custom marshaller. It replaces the schema of
+// known types, such as time.Time, so it can also be used to change their schemas.
+// Example:
+//
+//	openapi.RegisterKnownType[decimal.Decimal](api, *openapi3.NewStringSchema().WithPattern(`^-?[0-9]+(\.[0-9]+)?$`))
+func RegisterKnownType[T any](api *API, schema openapi3.Schema) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.invalidate()
+	api.rebuildModels = true
+	if api.KnownTypes == nil {
+		api.KnownTypes = make(map[reflect.Type]openapi3.Schema)
+	}
+	api.KnownTypes[reflect.TypeFor[T]()] = schema
+}
+
+// KnownType returns the schema of t, and true, if t is in KnownTypes, or is matched by one of
+// the KnownTypeMatchers.
+func (api *API) KnownType(t reflect.Type) (schema openapi3.Schema, ok bool) {
+	if schema, ok = api.KnownTypes[t]; ok {
+		return schema, true
+	}
+	for _, m := range api.KnownTypeMatchers {
+		if schema, ok = m(t); ok {
+			return schema, true
+		}
+	}
+	return schema, false
+}
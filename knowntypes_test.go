@@ -0,0 +1,102 @@
+package openapi
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type KnownTypesColour struct {
+	R, G, B uint8
+}
+
+func (c KnownTypesColour) String() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func TestKnownType(t *testing.T) {
+	stringer := reflect.TypeFor[fmt.Stringer]()
+	api := NewAPI("colours", WithKnownTypeMatcher(func(t reflect.Type) (openapi3.Schema, bool) {
+		if t.Kind() == reflect.Struct && t.Implements(stringer) {
+			return *openapi3.NewStringSchema(), true
+		}
+		return openapi3.Schema{}, false
+	}))
+	RegisterKnownType[int](api, *openapi3.NewStringSchema())
+
+	tests := []struct {
+		t              reflect.Type
+		expectedOK     bool
+		expectedType   string
+		expectedFormat string
+	}{
+		{t: reflect.TypeFor[KnownTypesColour](), expectedOK: true, expectedType: "string"},
+		{t: reflect.TypeFor[KnownTypesUUID](), expectedOK: true, expectedType: "string", expectedFormat: "uuid"},
+		{t: reflect.TypeFor[int](), expectedOK: true, expectedType: "string"},
+		{t: reflect.TypeFor[[16]byte]()},
+		{t: reflect.TypeFor[User]()},
+	}
+	for _, test := range tests {
+		schema, ok := api.KnownType(test.t)
+		if ok != test.expectedOK {
+			t.Errorf("%v: expected ok %v, got %v", test.t, test.expectedOK, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if !schema.Type.Is(test.expectedType) || schema.Format != test.expectedFormat {
+			t.Errorf("%v: expected %q %q, got %v %q", test.t, test.expectedType, test.expectedFormat, schema.Type, schema.Format)
+		}
+	}
+
+	if _, ok := NewAPI("other").KnownType(reflect.TypeFor[int]()); ok {
+		t.Error("expected registered known types not to be shared between APIs")
+	}
+}
+
+type KnownTypesEncoded struct {
+	Name     sql.NullString  `json:"name"`
+	Score    sql.Null[int]   `json:"score"`
+	Seen     sql.NullTime    `json:"seen"`
+	Homepage url.URL         `json:"homepage"`
+	Email    mail.Address    `json:"email"`
+	Duration time.Duration   `json:"duration"`
+	Number   json.Number     `json:"number"`
+	Raw      json.RawMessage `json:"raw"`
+}
+
+func TestKnownTypesMatchEncoding(t *testing.T) {
+	api := NewAPI("encoded")
+	api.Get("/encoded").HasResponseModel(http.StatusOK, ModelOf[KnownTypesEncoded]())
+	value := KnownTypesEncoded{
+		Name:     sql.NullString{String: "a", Valid: true},
+		Score:    sql.Null[int]{V: 1, Valid: true},
+		Seen:     sql.NullTime{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Valid: true},
+		Homepage: url.URL{Scheme: "https", Host: "example.com"},
+		Email:    mail.Address{Name: "Alice", Address: "alice@example.com"},
+		Duration: time.Second,
+		Number:   "1.5",
+		Raw:      json.RawMessage(`{"a":[1]}`),
+	}
+	v, err := NewResponseValidator(api, WithResponseViolationHandler(func(r *http.Request, v *ResponseViolation) {
+		t.Errorf("expected the encoded value to match the spec: %s", v)
+	}))
+	if err != nil {
+		t.Fatalf("failed to create response validator: %v", err)
+	}
+	h := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(value)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/encoded", nil))
+}
@@ -0,0 +1,516 @@
+package openapi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"regexp/syntax"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// MockOpts configures the handler returned by MockHandler.
+type MockOpts func(*mockHandler)
+
+// WithMockSeed sets the seed used to generate responses. The same seed, route
+// and status code always produce the same response. The default seed is 1.
+func WithMockSeed(seed int64) MockOpts {
+	return func(m *mockHandler) {
+		m.seed = seed
+	}
+}
+
+// MockHandler returns a handler that serves fake responses for the routes of the API,
+// before any real handlers exist. Responses conform to the registered models, and are
+// generated deterministically, respecting enums, formats, patterns and minimum and maximum
+// values. Requests are validated against the API first.
+//
+// JSON responses are encoded as JSON, and responses of other media types are only mocked if
+// their schema is a string, e.g. text/plain, otherwise the handler responds with 501 Not
+// Implemented.
+//
+// By default, the lowest declared 2xx response is served. The Prefer header selects
+// a different response:
+//
+//	Prefer: code=404        serves the response declared for the status code, or the default response.
+//	Prefer: example=admin   serves the named example, see Route.HasResponseExample.
+//	Prefer: echo            serves the validated request body back to the client.
+func (api *API) MockHandler(opts ...MockOpts) http.Handler {
+	m := &mockHandler{seed: 1}
+	for _, o := range opts {
+		o(m)
+	}
+	spec, err := api.Spec()
+	if err != nil {
+		m.err = fmt.Errorf("create spec err: %w", err)
+		return m
+	}
+	if m.validator, err = newRequestValidator(spec); err != nil {
+		m.err = err
+		return m
+	}
+	m.router = m.validator.router
+	return m
+}
+
+type mockHandler struct {
+	seed      int64
+	err       error
+	router    routers.Router
+	validator *RequestValidator
+}
+
+func (m *mockHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if m.err != nil {
+		WriteProblem(w, NewProblemDetails(http.StatusInternalServerError, m.err.Error()))
+		return
+	}
+	route, _, err := findRoute(m.router, r)
+	if err != nil {
+		WriteProblem(w, routeProblem(err))
+		return
+	}
+	if p := m.validator.Validate(r); p != nil {
+		p.Instance = r.URL.Path
+		WriteProblem(w, p)
+		return
+	}
+	prefer := parsePrefer(r.Header.Values("Prefer"))
+
+	status, response, err := selectMockResponse(route.Operation, prefer["code"])
+	if err != nil {
+		WriteProblem(w, NewProblemDetails(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if _, ok := prefer["echo"]; ok {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			WriteProblem(w, NewProblemDetails(http.StatusBadRequest, err.Error()))
+			return
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "" {
+			w.Header().Set("Content-Type", ct)
+		}
+		w.WriteHeader(status)
+		w.Write(body)
+		return
+	}
+
+	g := newMockGenerator(m.seed, r.Method, route.Path, strconv.Itoa(status))
+	if response != nil {
+		for _, name := range getSortedKeys(response.Headers) {
+			h := response.Headers[name]
+			if h == nil || h.Value == nil || h.Value.Schema == nil {
+				continue
+			}
+			w.Header().Set(name, fmt.Sprint(g.value(h.Value.Schema, 0)))
+		}
+	}
+	if response == nil || len(response.Content) == 0 {
+		w.WriteHeader(status)
+		return
+	}
+	mediaType := mockMediaType(response.Content)
+	content := response.Content[mediaType]
+
+	var value any
+	if name, ok := prefer["example"]; ok {
+		example, ok := content.Examples[name]
+		if !ok || example.Value == nil {
+			WriteProblem(w, NewProblemDetails(http.StatusBadRequest, fmt.Sprintf("example %q is not declared for status %d", name, status)))
+			return
+		}
+		value = example.Value.Value
+	} else if content.Schema != nil {
+		value = g.value(content.Schema, 0)
+	}
+	body, err := encodeMockBody(mediaType, value)
+	if err != nil {
+		WriteProblem(w, NewProblemDetails(http.StatusNotImplemented, err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", mediaType)
+	if len(prefer) > 0 {
+		w.Header().Set("Preference-Applied", strings.Join(getSortedKeys(prefer), ", "))
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// mockMediaType returns application/json, or another JSON media type, if the response has one,
+// or else the first media type of the response.
+func mockMediaType(content openapi3.Content) string {
+	mediaTypes := getSortedKeys(content)
+	if _, ok := content["application/json"]; ok {
+		return "application/json"
+	}
+	for _, mt := range mediaTypes {
+		if isJSONMediaType(mt) || mt == ContentTypeNDJSON {
+			return mt
+		}
+	}
+	return mediaTypes[0]
+}
+
+// encodeMockBody encodes the value as JSON for JSON media types, and as text for strings. Other
+// values can't be encoded for other media types, e.g. XML, so return an error.
+func encodeMockBody(mediaType string, value any) ([]byte, error) {
+	if isJSONMediaType(mediaType) || mediaType == ContentTypeNDJSON {
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("encode mock response err: %w", err)
+		}
+		return append(b, '\n'), nil
+	}
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(v), nil
+	}
+	return nil, fmt.Errorf("mock responses of %s content aren't supported", mediaType)
+}
+
+// parsePrefer parses RFC 7240 Prefer headers, e.g. "code=404, example=admin".
+func parsePrefer(values []string) map[string]string {
+	prefer := make(map[string]string)
+	for _, v := range values {
+		for _, p := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ';' }) {
+			k, v, _ := strings.Cut(strings.TrimSpace(p), "=")
+			if k == "" {
+				continue
+			}
+			prefer[strings.ToLower(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return prefer
+}
+
+// selectMockResponse returns the preferred response, or the lowest 2xx response of the operation.
+// Preferred status codes that aren't declared use the default response, if the API declares one.
+func selectMockResponse(op *openapi3.Operation, preferredCode string) (status int, response *openapi3.Response, err error) {
+	responses := op.Responses.Map()
+	if preferredCode != "" {
+		status, err = strconv.Atoi(preferredCode)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid preferred status code %q", preferredCode)
+		}
+		ref := op.Responses.Status(status)
+		if ref == nil && isDocumentedResponse(op.Responses.Default()) {
+			ref = op.Responses.Default()
+		}
+		if ref == nil {
+			return 0, nil, fmt.Errorf("status %d is not declared", status)
+		}
+		return status, ref.Value, nil
+	}
+	var codes []int
+	for k := range responses {
+		if code, err := strconv.Atoi(k); err == nil {
+			codes = append(codes, code)
+		}
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		if code >= 200 && code < 300 {
+			return code, responses[strconv.Itoa(code)].Value, nil
+		}
+	}
+	if len(codes) > 0 {
+		return codes[0], responses[strconv.Itoa(codes[0])].Value, nil
+	}
+	return http.StatusOK, nil, nil
+}
+
+// maxMockDepth limits the expansion of recursive schemas.
+const maxMockDepth = 8
+
+// mockGenerator creates values that conform to a schema.
+type mockGenerator struct {
+	r *rand.Rand
+}
+
+func newMockGenerator(seed int64, keys ...string) *mockGenerator {
+	h := fnv.New64a()
+	for _, k := range keys {
+		h.Write([]byte(k))
+	}
+	return &mockGenerator{r: rand.New(rand.NewSource(seed ^ int64(h.Sum64())))}
+}
+
+func (g *mockGenerator) value(sr *openapi3.SchemaRef, depth int) any {
+	if sr == nil || sr.Value == nil {
+		return nil
+	}
+	s := sr.Value
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case len(s.Enum) > 0:
+		return s.Enum[g.r.Intn(len(s.Enum))]
+	case len(s.OneOf) > 0:
+		return g.value(s.OneOf[g.r.Intn(len(s.OneOf))], depth+1)
+	case len(s.AnyOf) > 0:
+		return g.value(s.AnyOf[g.r.Intn(len(s.AnyOf))], depth+1)
+	case len(s.AllOf) > 0:
+		m := make(map[string]any)
+		for _, sub := range s.AllOf {
+			if v, ok := g.value(sub, depth+1).(map[string]any); ok {
+				for k, vv := range v {
+					m[k] = vv
+				}
+			}
+		}
+		return m
+	}
+	switch {
+	case s.Type.Is(openapi3.TypeString):
+		return g.string(s)
+	case s.Type.Is(openapi3.TypeInteger):
+		return int64(g.number(s, true))
+	case s.Type.Is(openapi3.TypeNumber):
+		return g.number(s, false)
+	case s.Type.Is(openapi3.TypeBoolean):
+		return g.r.Intn(2) == 1
+	case s.Type.Is(openapi3.TypeArray):
+		return g.array(s, depth)
+	case s.Type.Is(openapi3.TypeObject) || len(s.Properties) > 0:
+		return g.object(s, depth)
+	}
+	return nil
+}
+
+func (g *mockGenerator) array(s *openapi3.Schema, depth int) []any {
+	minItems, maxItems := int(s.MinItems), int(s.MinItems)+3
+	if s.MaxItems != nil {
+		maxItems = int(*s.MaxItems)
+	}
+	if depth >= maxMockDepth {
+		maxItems = minItems
+	}
+	n := minItems
+	if maxItems > minItems {
+		n += g.r.Intn(maxItems - minItems + 1)
+	}
+	items := make([]any, 0, n)
+	for i := 0; i < n; i++ {
+		items = append(items, g.value(s.Items, depth+1))
+	}
+	return items
+}
+
+func (g *mockGenerator) object(s *openapi3.Schema, depth int) map[string]any {
+	m := make(map[string]any)
+	for _, name := range getSortedKeys(s.Properties) {
+		p := s.Properties[name]
+		required := slices.Contains(s.Required, name)
+		if p == nil || p.Value == nil || p.Value.WriteOnly {
+			continue
+		}
+		if !required && depth >= maxMockDepth {
+			continue
+		}
+		if depth >= maxMockDepth && p.Value.Nullable {
+			m[name] = nil
+			continue
+		}
+		m[name] = g.value(p, depth+1)
+	}
+	if s.AdditionalProperties.Schema != nil && depth < maxMockDepth {
+		for i := 0; i < 2; i++ {
+			m[fmt.Sprintf("key%d", i+1)] = g.value(s.AdditionalProperties.Schema, depth+1)
+		}
+	}
+	return m
+}
+
+func (g *mockGenerator) number(s *openapi3.Schema, integer bool) float64 {
+	lo, hi := 0.0, 1000.0
+	if s.Min != nil {
+		lo = *s.Min
+		if s.Max == nil {
+			hi = lo + 1000
+		}
+		if s.ExclusiveMin {
+			lo = math.Nextafter(lo, math.Inf(1))
+			if integer {
+				lo = math.Floor(*s.Min) + 1
+			}
+		}
+	}
+	if s.Max != nil {
+		hi = *s.Max
+		if s.Min == nil && hi < lo {
+			lo = hi - 1000
+		}
+		if s.ExclusiveMax {
+			hi = math.Nextafter(hi, math.Inf(-1))
+			if integer {
+				hi = math.Ceil(*s.Max) - 1
+			}
+		}
+	}
+	if integer {
+		lo, hi = math.Ceil(lo), math.Floor(hi)
+	}
+	if hi <= lo {
+		return lo
+	}
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		step := *s.MultipleOf
+		first, last := math.Ceil(lo/step), math.Floor(hi/step)
+		if last < first {
+			return lo
+		}
+		return (first + g.intn(last-first)) * step
+	}
+	if integer {
+		return lo + g.intn(hi-lo)
+	}
+	v := lo + g.r.Float64()*(hi-lo)
+	// Round to two decimal places, unless the value is too large to be multiplied.
+	if rounded := math.Round(v*100) / 100; !math.IsInf(rounded, 0) && rounded >= lo && rounded <= hi {
+		return rounded
+	}
+	return v
+}
+
+// intn returns a random integer between 0 and n, inclusive. Ranges that are too wide for an int64,
+// e.g. from math.MinInt64 to math.MaxInt64, use float arithmetic instead.
+func (g *mockGenerator) intn(n float64) float64 {
+	if n >= 1<<62 {
+		return math.Floor(g.r.Float64() * n)
+	}
+	return float64(g.r.Int63n(int64(n) + 1))
+}
+
+const mockLetters = "abcdefghijklmnopqrstuvwxyz"
+
+func (g *mockGenerator) string(s *openapi3.Schema) string {
+	switch s.Format {
+	case "date-time":
+		return g.time().Format(time.RFC3339)
+	case "date":
+		return g.time().Format(time.DateOnly)
+	case "time":
+		return g.time().Format(time.TimeOnly)
+	case "email":
+		return g.letters(8) + "@example.com"
+	case "uuid":
+		b := make([]byte, 16)
+		g.r.Read(b)
+		b[6] = (b[6] & 0x0f) | 0x40
+		b[8] = (b[8] & 0x3f) | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	case "uri", "url":
+		return "https://example.com/" + g.letters(8)
+	case "hostname":
+		return g.letters(8) + ".example.com"
+	case "ipv4":
+		return fmt.Sprintf("192.0.2.%d", g.r.Intn(255)+1)
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x", g.r.Intn(0xffff)+1)
+	case "byte":
+		b := make([]byte, 8)
+		g.r.Read(b)
+		return base64.StdEncoding.EncodeToString(b)
+	}
+	if s.Pattern != "" {
+		if v, ok := g.pattern(s.Pattern); ok {
+			return v
+		}
+	}
+	minLength, maxLength := int(s.MinLength), int(s.MinLength)+8
+	if s.MaxLength != nil {
+		maxLength = int(*s.MaxLength)
+	}
+	n := minLength
+	if maxLength > minLength {
+		n += g.r.Intn(maxLength - minLength + 1)
+	}
+	return g.letters(n)
+}
+
+func (g *mockGenerator) time() time.Time {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return start.Add(time.Duration(g.r.Int63n(365*24*60*60)) * time.Second)
+}
+
+func (g *mockGenerator) letters(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = mockLetters[g.r.Intn(len(mockLetters))]
+	}
+	return string(b)
+}
+
+// pattern generates a string that matches the regular expression.
+func (g *mockGenerator) pattern(pattern string) (string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var sb strings.Builder
+	g.regexp(&sb, re.Simplify())
+	return sb.String(), true
+}
+
+func (g *mockGenerator) regexp(sb *strings.Builder, re *syntax.Regexp) {
+	repeat := func(min, max int) {
+		if max < 0 {
+			max = min + 3
+		}
+		n := min
+		if max > min {
+			n += g.r.Intn(max - min + 1)
+		}
+		for i := 0; i < n; i++ {
+			g.regexp(sb, re.Sub[0])
+		}
+	}
+	switch re.Op {
+	case syntax.OpLiteral:
+		sb.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		if len(re.Rune) < 2 {
+			return
+		}
+		i := g.r.Intn(len(re.Rune)/2) * 2
+		lo, hi := re.Rune[i], re.Rune[i+1]
+		// Prefer printable ASCII characters from negated or very wide classes.
+		if hi > '~' {
+			hi = max(lo, '~')
+		}
+		sb.WriteRune(lo + rune(g.r.Intn(int(hi-lo)+1)))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteByte(mockLetters[g.r.Intn(len(mockLetters))])
+	case syntax.OpCapture:
+		g.regexp(sb, re.Sub[0])
+	case syntax.OpStar:
+		repeat(0, 3)
+	case syntax.OpPlus:
+		repeat(1, 3)
+	case syntax.OpQuest:
+		repeat(0, 1)
+	case syntax.OpRepeat:
+		repeat(re.Min, re.Max)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			g.regexp(sb, sub)
+		}
+	case syntax.OpAlternate:
+		g.regexp(sb, re.Sub[g.r.Intn(len(re.Sub))])
+	}
+}
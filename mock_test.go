@@ -0,0 +1,221 @@
+package openapi
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type MockStatus string
+
+type MockTopic struct {
+	ID        string     `json:"id"`
+	Status    MockStatus `json:"status"`
+	Code      string     `json:"code"`
+	Priority  int        `json:"priority"`
+	CreatedAt time.Time  `json:"createdAt"`
+	Tags      []string   `json:"tags"`
+}
+
+func (MockTopic) ApplyCustomSchema(s *openapi3.Schema) {
+	s.Properties["id"].Value.Format = "uuid"
+	s.Properties["code"].Value.Pattern = `^[A-Z]{3}-\d{4}$`
+	s.Properties["priority"].Value.WithMin(1).WithMax(5)
+}
+
+type MockError struct {
+	Message string `json:"message"`
+}
+
+func newMockAPI() *API {
+	api := NewAPI("mock")
+	api.StripPkgPaths = []string{"github.com/ihezebin/openapi"}
+	api.RegisterModel(ModelOf[MockStatus](), WithEnumValues[MockStatus]("open", "closed"))
+	api.Get("/topic/{id}").
+		HasPathParameter("id", PathParam{}).
+		HasResponseModel(http.StatusOK, ModelOf[MockTopic]()).
+		HasResponseModel(http.StatusNotFound, ModelOf[MockError]()).
+		HasResponseExample(http.StatusNotFound, "missing", MockError{Message: "topic not found"}).
+		HasResponseHeader(http.StatusOK, "X-Total", HeaderParam{Type: PrimitiveTypeInteger}).
+		HasResponseModel(0, ModelOf[MockError]())
+	api.Post("/topic").
+		HasRequestModel(ModelOf[MockError]()).
+		HasResponseModel(http.StatusCreated, ModelOf[MockError]())
+	return api
+}
+
+func TestMockHandler(t *testing.T) {
+	api := newMockAPI()
+	h := api.MockHandler(WithMockSeed(42))
+	v, err := NewResponseValidator(api, WithResponseViolationHandler(func(r *http.Request, v *ResponseViolation) {
+		t.Errorf("mock response doesn't match the API: %s", v)
+	}))
+	if err != nil {
+		t.Fatalf("failed to create response validator: %v", err)
+	}
+	h = v.Middleware(h)
+
+	get := func(prefer string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/topic/123", nil)
+		if prefer != "" {
+			r.Header.Set("Prefer", prefer)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("responses are schema conformant and deterministic", func(t *testing.T) {
+		a, b := get(""), get("")
+		if a.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", a.Code, a.Body.String())
+		}
+		if a.Body.String() != b.Body.String() {
+			t.Errorf("expected the same response for the same seed:\n%s\n%s", a.Body.String(), b.Body.String())
+		}
+		if a.Header().Get("X-Total") == "" {
+			t.Error("expected the response header to be generated")
+		}
+		var topic MockTopic
+		if err := json.Unmarshal(a.Body.Bytes(), &topic); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if topic.Status != "open" && topic.Status != "closed" {
+			t.Errorf("expected an enum value, got %q", topic.Status)
+		}
+		if topic.Priority < 1 || topic.Priority > 5 {
+			t.Errorf("expected priority within 1 and 5, got %d", topic.Priority)
+		}
+	})
+	t.Run("a different seed produces a different response", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		api.MockHandler(WithMockSeed(7)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/topic/123", nil))
+		if w.Body.String() == get("").Body.String() {
+			t.Error("expected a different response for a different seed")
+		}
+	})
+	t.Run("prefer code", func(t *testing.T) {
+		w := get("code=404")
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", w.Code)
+		}
+	})
+	t.Run("prefer example", func(t *testing.T) {
+		w := get("code=404, example=missing")
+		if !strings.Contains(w.Body.String(), "topic not found") {
+			t.Errorf("expected the named example, got %s", w.Body.String())
+		}
+	})
+	t.Run("prefer undeclared code", func(t *testing.T) {
+		w := get("code=418")
+		var e MockError
+		if w.Code != http.StatusTeapot || json.Unmarshal(w.Body.Bytes(), &e) != nil {
+			t.Errorf("expected the default response with status 418, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+	t.Run("prefer undeclared code without a default response", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/topic", strings.NewReader(`{"message":"hello"}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Prefer", "code=418")
+		api.MockHandler().ServeHTTP(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestMockHandlerMediaTypes(t *testing.T) {
+	api := NewAPI("mock")
+	api.Get("/summary").HasResponseContent(http.StatusOK, "text/plain", ModelOf[string]())
+	api.Get("/feed").HasResponseContent(http.StatusOK, "application/atom+xml", ModelOf[MockError]())
+	api.Get("/problem").HasResponseContent(http.StatusOK, ContentTypeProblemJSON, ModelOf[MockError]())
+	h := api.MockHandler()
+
+	tests := []struct {
+		path               string
+		expectedStatus     int
+		expectedMediaType  string
+		expectedJSONObject bool
+	}{
+		{path: "/summary", expectedStatus: http.StatusOK, expectedMediaType: "text/plain"},
+		{path: "/feed", expectedStatus: http.StatusNotImplemented, expectedMediaType: ContentTypeProblemJSON, expectedJSONObject: true},
+		{path: "/problem", expectedStatus: http.StatusOK, expectedMediaType: ContentTypeProblemJSON, expectedJSONObject: true},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, test.path, nil))
+			if w.Code != test.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", test.expectedStatus, w.Code, w.Body.String())
+			}
+			if actual := w.Header().Get("Content-Type"); actual != test.expectedMediaType {
+				t.Errorf("expected content type %q, got %q", test.expectedMediaType, actual)
+			}
+			var v map[string]any
+			if isObject := json.Unmarshal(w.Body.Bytes(), &v) == nil; isObject != test.expectedJSONObject {
+				t.Errorf("expected JSON object %v, got %s", test.expectedJSONObject, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestMockHandlerEcho(t *testing.T) {
+	h := newMockAPI().MockHandler()
+
+	r := httptest.NewRequest(http.MethodPost, "/topic", strings.NewReader(`{"message":"hello"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Prefer", "echo")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusCreated || w.Body.String() != `{"message":"hello"}` {
+		t.Errorf("expected the request to be echoed, got %d: %s", w.Code, w.Body.String())
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/topic", strings.NewReader(`{"message":1}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Prefer", "echo")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected invalid requests to be rejected, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestMockGeneratorPattern(t *testing.T) {
+	g := newMockGenerator(1)
+	for _, pattern := range []string{`^[A-Z]{3}-\d{4}$`, `^(foo|bar)+[a-f0-9]*$`, `^\w+@\w+\.com$`} {
+		s := &openapi3.Schema{Type: &openapi3.Types{openapi3.TypeString}, Pattern: pattern}
+		for i := 0; i < 10; i++ {
+			v := g.value(s.NewRef(), 0)
+			if err := s.VisitJSON(v); err != nil {
+				t.Errorf("generated value %q doesn't match %q: %v", v, pattern, err)
+			}
+		}
+	}
+}
+
+func TestMockGeneratorWideRanges(t *testing.T) {
+	g := newMockGenerator(1)
+	even := openapi3.NewInt64Schema().WithMin(math.MinInt64).WithMax(math.MaxInt64)
+	even.MultipleOf = openapi3.Float64Ptr(2)
+	schemas := []*openapi3.Schema{
+		openapi3.NewInt64Schema().WithMin(math.MinInt64).WithMax(math.MaxInt64),
+		even,
+		openapi3.NewFloat64Schema().WithMin(-math.MaxFloat64 / 2).WithMax(math.MaxFloat64 / 2),
+	}
+	for _, s := range schemas {
+		for i := 0; i < 10; i++ {
+			v := g.value(s.NewRef(), 0)
+			if err := s.VisitJSON(v); err != nil {
+				t.Errorf("generated value %v doesn't match the schema: %v", v, err)
+			}
+		}
+	}
+}
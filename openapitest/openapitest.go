@@ -0,0 +1,46 @@
+// Package openapitest provides utilities for checking that HTTP handlers
+// respond as declared in an API.
+package openapitest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ihezebin/openapi"
+)
+
+// NewResponseValidator creates a response validator that fails the test for each response
+// that doesn't match the API, reporting the JSON pointer of each invalid value, and any
+// undocumented status codes.
+func NewResponseValidator(t testing.TB, api *openapi.API) *openapi.ResponseValidator {
+	t.Helper()
+	v, err := openapi.NewResponseValidator(api, openapi.WithResponseViolationHandler(func(r *http.Request, v *openapi.ResponseViolation) {
+		t.Errorf("response doesn't match the API:\n%s", v)
+	}))
+	if err != nil {
+		t.Fatalf("failed to create response validator: %v", err)
+	}
+	return v
+}
+
+// Handler wraps h, so that every response it writes is checked against the API.
+func Handler(t testing.TB, api *openapi.API, h http.Handler) http.Handler {
+	t.Helper()
+	return NewResponseValidator(t, api).Middleware(h)
+}
+
+// AssertResponse checks the recorded response to r against the API, failing the
+// test if it doesn't match. It returns true if the response is valid.
+func AssertResponse(t testing.TB, api *openapi.API, r *http.Request, w *httptest.ResponseRecorder) bool {
+	t.Helper()
+	v, err := openapi.NewResponseValidator(api)
+	if err != nil {
+		t.Fatalf("failed to create response validator: %v", err)
+	}
+	if violation := v.Validate(r, w.Code, w.Header(), w.Body.Bytes()); violation != nil {
+		t.Errorf("response doesn't match the API:\n%s", violation)
+		return false
+	}
+	return true
+}
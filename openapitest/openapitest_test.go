@@ -0,0 +1,58 @@
+package openapitest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ihezebin/openapi"
+)
+
+type Topic struct {
+	Name string `json:"name"`
+}
+
+func TestHandler(t *testing.T) {
+	api := openapi.NewAPI("topics")
+	api.Get("/topic").HasResponseModel(http.StatusOK, openapi.ModelOf[Topic]())
+
+	h := Handler(t, api, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": "a"}`))
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/topic", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	AssertResponse(t, api, r, w)
+}
+
+func TestAssertResponseFailsOnInvalidResponse(t *testing.T) {
+	api := openapi.NewAPI("topics")
+	api.Get("/topic").HasResponseModel(http.StatusOK, openapi.ModelOf[Topic]())
+
+	r := httptest.NewRequest(http.MethodGet, "/topic", nil)
+	w := httptest.NewRecorder()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"name": 1}`))
+
+	ft := &fakeT{TB: t}
+	if AssertResponse(ft, api, r, w) {
+		t.Error("expected invalid response to fail")
+	}
+	if !ft.failed {
+		t.Error("expected the test to be marked as failed")
+	}
+}
+
+type fakeT struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.failed = true
+}
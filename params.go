@@ -0,0 +1,609 @@
+package openapi
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// paramField is the struct field that a parameter was created from.
+type paramField struct {
+	pkgPath  string
+	typeName string
+	name     string
+	// validate is the validate tag of the field, e.g. min=1,max=100.
+	validate string
+}
+
+// paramTags are the struct tags that map fields to parameters, named after the parameter location.
+var paramTags = []string{
+	openapi3.ParameterInPath,
+	openapi3.ParameterInQuery,
+	openapi3.ParameterInHeader,
+	openapi3.ParameterInCookie,
+}
+
+// taggedField is a struct field with a parameter tag.
+type taggedField struct {
+	// index of the field, for use with reflect.Value.FieldByIndex.
+	index    []int
+	in       string
+	name     string
+	required bool
+	typ      reflect.Type
+	source   *paramField
+	// style, explode and allowReserved are set with the style=, explode= and allowReserved
+	// options of the tag.
+	style         string
+	explode       *bool
+	allowReserved bool
+}
+
+// ParamsOf creates the parameters of a route from the fields of the struct T that have a
+// path, query, header or cookie tag. For example:
+//
+//	type ListUsersParams struct {
+//		// Limit is the maximum number of users to return.
+//		Limit  int      `query:"limit,omitempty"`
+//		Status []Status `query:"status,omitempty"`
+//		Tenant string   `header:"X-Tenant"`
+//	}
+//
+//	api.Get("/users").HasParams(openapi.ParamsOf[ListUsersParams]())
+//
+// The schema of each parameter is created from the type of the field, so slices, enums and
+// time.Time are supported, and structs and maps are object parameters. Parameters are required,
+// unless the field is a pointer, or the tag includes omitempty. Path parameters are always
+// required. The comment of the field is used as the description of the parameter, and the
+// limits of its validate tag, e.g. `validate:"min=1,max=100"`, are added to its schema.
+//
+// The serialization of the parameter is set with the style=, explode= and allowReserved options
+// of the tag, e.g. `query:"filter,style=deepObject"` for ?filter[status]=open, or
+// `query:"tags,explode=false"` for ?tags=a,b.
+//
+// ParamsOf panics if T is not a struct.
+func ParamsOf[T any]() Params {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("openapi: ParamsOf: %v is not a struct", t))
+	}
+	p := Params{
+		Path:   make(map[string]PathParam),
+		Query:  make(map[string]QueryParam),
+		Header: make(map[string]HeaderParam),
+		Cookie: make(map[string]CookieParam),
+	}
+	for _, f := range taggedFields(t) {
+		m := modelFromType(f.typ)
+		switch f.in {
+		case openapi3.ParameterInPath:
+			p.Path[f.name] = PathParam{Model: m, Style: f.style, Explode: f.explode, field: f.source}
+		case openapi3.ParameterInQuery:
+			p.Query[f.name] = QueryParam{Model: m, Required: f.required, Style: f.style, Explode: f.explode, AllowReserved: f.allowReserved, field: f.source}
+		case openapi3.ParameterInHeader:
+			p.Header[f.name] = HeaderParam{Model: m, Required: f.required, Explode: f.explode, field: f.source}
+		case openapi3.ParameterInCookie:
+			p.Cookie[f.name] = CookieParam{Model: m, Required: f.required, Explode: f.explode, field: f.source}
+		}
+	}
+	return p
+}
+
+// PathOf creates a path parameter with the schema of T, e.g. PathOf[uuid.UUID]("ID of the user.").
+// The schema is created in the same way as the schema of a model, so enums are referenced
+// components, and time.Time is a date-time.
+func PathOf[T any](description string) PathParam {
+	return PathParam{Description: description, Model: ModelOf[T]()}
+}
+
+// QueryOf creates a query parameter with the schema of T, e.g. QueryOf[[]Status]("Statuses to include.").
+// The schema is created in the same way as the schema of a model, so enums are referenced
+// components, and time.Time is a date-time.
+func QueryOf[T any](description string) QueryParam {
+	return QueryParam{Description: description, Model: ModelOf[T]()}
+}
+
+// HeaderOf creates a header parameter with the schema of T, e.g. HeaderOf[int]("Version of the API.").
+// The schema is created in the same way as the schema of a model, so enums are referenced
+// components, and time.Time is a date-time.
+func HeaderOf[T any](description string) HeaderParam {
+	return HeaderParam{Description: description, Model: ModelOf[T]()}
+}
+
+// CookieOf creates a cookie parameter with the schema of T, e.g. CookieOf[string]("Session ID.").
+// The schema is created in the same way as the schema of a model, so enums are referenced
+// components, and time.Time is a date-time.
+func CookieOf[T any](description string) CookieParam {
+	return CookieParam{Description: description, Model: ModelOf[T]()}
+}
+
+// HasParams adds the parameters to the route, replacing any parameters with the same name and location.
+// Example:
+//
+//	api.Get("/users").HasParams(openapi.ParamsOf[ListUsersParams]())
+func (rm *Route) HasParams(p Params) *Route {
+	return rm.update(func() {
+		for k, v := range p.Path {
+			rm.Params.Path[k] = v
+		}
+		for k, v := range p.Query {
+			rm.Params.Query[k] = v
+		}
+		for k, v := range p.Header {
+			rm.Params.Header[k] = v
+		}
+		for k, v := range p.Cookie {
+			rm.Params.Cookie[k] = v
+		}
+	})
+}
+
+// taggedFields returns the fields of the struct that have a parameter tag, including the fields of embedded structs.
+func taggedFields(t reflect.Type) (op []taggedField) {
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() {
+			continue
+		}
+		for _, in := range paramTags {
+			tag, ok := f.Tag.Lookup(in)
+			if !ok {
+				continue
+			}
+			options := strings.Split(tag, ",")
+			name := options[0]
+			if name == "" {
+				name = f.Name
+			}
+			typ := f.Type
+			if typ.Kind() == reflect.Pointer {
+				typ = typ.Elem()
+			}
+			parent := t
+			for _, i := range f.Index[:len(f.Index)-1] {
+				parent = parent.Field(i).Type
+				if parent.Kind() == reflect.Pointer {
+					parent = parent.Elem()
+				}
+			}
+			tf := taggedField{
+				index:    f.Index,
+				in:       in,
+				name:     name,
+				required: in == openapi3.ParameterInPath || isFieldRequired(f.Type.Kind() == reflect.Pointer, slices.Contains(options[1:], "omitempty")),
+				typ:      typ,
+				source:   &paramField{pkgPath: parent.PkgPath(), typeName: parent.Name(), name: f.Name, validate: f.Tag.Get("validate")},
+			}
+			for _, o := range options[1:] {
+				key, value, _ := strings.Cut(o, "=")
+				switch key {
+				case "style":
+					tf.style = value
+				case "explode":
+					explode := value != "false"
+					tf.explode = &explode
+				case "allowReserved":
+					tf.allowReserved = true
+				}
+			}
+			op = append(op, tf)
+			break
+		}
+	}
+	return op
+}
+
+// hasBodyFields returns true if the type has fields that aren't parameters, and so are read from
+// the request body. Types that aren't structs are always read from the body.
+func hasBodyFields(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous || f.Tag.Get("json") == "-" {
+			continue
+		}
+		if !slices.ContainsFunc(paramTags, func(in string) bool {
+			_, ok := f.Tag.Lookup(in)
+			return ok
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+// BindParams creates a T from the path, query, header and cookie parameters of the request,
+// using the struct tags described in ParamsOf. Path parameters are read with r.PathValue, so
+// are available when the request is routed by http.ServeMux.
+//
+// Parameters are decoded using their style, in the same way that they're documented. By default,
+// query parameters with slice types are read from repeated values, e.g. ?tags=a&tags=b, while
+// header, cookie and path parameters are comma separated. Struct and map types are object
+// parameters, e.g. ?filter[status]=open in the deepObject style. Types that implement
+// encoding.TextUnmarshaler, such as time.Time, are parsed with UnmarshalText.
+//
+// If parameters are missing or invalid, a *ProblemDetails with the status 400 Bad Request
+// is returned, with each problem listed in its "errors" extension.
+func BindParams[T any](r *http.Request) (params T, err error) {
+	err = bindParams(r, reflect.ValueOf(&params).Elem())
+	return params, err
+}
+
+func bindParams(r *http.Request, v reflect.Value) error {
+	fields := taggedFields(v.Type())
+	// The names of query parameters, which aren't properties of exploded form objects.
+	queryNames := make(map[string]bool)
+	for _, f := range fields {
+		if f.in == openapi3.ParameterInQuery {
+			queryNames[f.name] = true
+		}
+	}
+	var errs []ValidationError
+	for _, f := range fields {
+		value, found, err := readParam(r, f, queryNames)
+		if err == nil && !found {
+			if f.required {
+				errs = append(errs, ValidationError{In: f.in, Name: f.name, Detail: "required parameter is missing"})
+			}
+			continue
+		}
+		if err == nil {
+			err = value.set(fieldByIndex(v, f.index))
+		}
+		if err != nil {
+			errs = append(errs, ValidationError{In: f.in, Name: f.name, Detail: err.Error()})
+		}
+	}
+	if len(errs) > 0 {
+		p := NewProblemDetails(http.StatusBadRequest, "The request parameters are not valid.")
+		p.Extensions = map[string]any{
+			"errors": errs,
+		}
+		return p
+	}
+	return nil
+}
+
+// fieldByIndex returns the nested field, allocating embedded struct pointers as required.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// ParamStyle returns the style of a parameter in the location, e.g. "query", and whether its
+// arrays and objects are exploded, using the defaults of the location for the style and explode
+// values that aren't set. It's used by client generators to serialize parameters in the same
+// way that BindParams decodes them.
+func ParamStyle(in, style string, explode *bool) (string, bool) {
+	ps := newParamStyle(in, style, explode)
+	return ps.style, ps.explode
+}
+
+// paramStyle is how a parameter is serialized, see https://spec.openapis.org/oas/v3.0.3#style-values
+type paramStyle struct {
+	style   string
+	explode bool
+}
+
+// newParamStyle returns the style of a parameter, using the defaults of its location for the
+// style and explode values that aren't set.
+func newParamStyle(in, style string, explode *bool) paramStyle {
+	if style == "" {
+		style = openapi3.SerializationSimple
+		if in == openapi3.ParameterInQuery || in == openapi3.ParameterInCookie {
+			style = openapi3.SerializationForm
+		}
+	}
+	ps := paramStyle{
+		style:   style,
+		explode: style == openapi3.SerializationForm || style == openapi3.SerializationDeepObject,
+	}
+	if explode != nil {
+		ps.explode = *explode
+	}
+	return ps
+}
+
+type paramKind int
+
+const (
+	paramKindPrimitive paramKind = iota
+	paramKindArray
+	paramKindObject
+)
+
+// getParamKind returns whether the type is sent as a primitive, array or object parameter.
+// Types that implement encoding.TextUnmarshaler are primitives.
+func getParamKind(t reflect.Type) paramKind {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return paramKindPrimitive
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return paramKindArray
+	case reflect.Struct:
+		return paramKindObject
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String {
+			return paramKindObject
+		}
+	}
+	return paramKindPrimitive
+}
+
+// paramValue is the value of a parameter, split into array items or object properties.
+type paramValue struct {
+	kind       paramKind
+	values     []string
+	properties map[string]string
+}
+
+// readParam reads the value of the parameter from the request, and decodes it using the style of
+// the parameter. The names of the query parameters are used to find the properties of exploded
+// form objects, e.g. ?status=open&limit=10, which are sent as query parameters of their own.
+func readParam(r *http.Request, f taggedField, queryNames map[string]bool) (v paramValue, found bool, err error) {
+	ps := newParamStyle(f.in, f.style, f.explode)
+	v.kind = getParamKind(f.typ)
+	if f.in == openapi3.ParameterInQuery {
+		return readQueryParam(r, f, ps, v.kind, queryNames)
+	}
+	var s string
+	switch f.in {
+	case openapi3.ParameterInPath:
+		s = r.PathValue(f.name)
+	case openapi3.ParameterInHeader:
+		s = strings.Join(r.Header.Values(f.name), ",")
+	case openapi3.ParameterInCookie:
+		if c, err := r.Cookie(f.name); err == nil {
+			s = c.Value
+		}
+	}
+	if s == "" {
+		return v, false, nil
+	}
+	v, err = decodeDelimited(s, f.name, ps, v.kind)
+	return v, true, err
+}
+
+func readQueryParam(r *http.Request, f taggedField, ps paramStyle, kind paramKind, queryNames map[string]bool) (v paramValue, found bool, err error) {
+	q := r.URL.Query()
+	v.kind = kind
+	values, found := q[f.name]
+	if kind == paramKindObject && ps.explode {
+		v.properties = make(map[string]string)
+		properties := objectFields(f.typ)
+		for key, values := range q {
+			switch {
+			case ps.style == openapi3.SerializationDeepObject:
+				if name, ok := strings.CutPrefix(key, f.name+"["); ok && strings.HasSuffix(name, "]") {
+					v.properties[strings.TrimSuffix(name, "]")] = values[0]
+				}
+			case properties != nil && properties[key] != nil,
+				properties == nil && !queryNames[key]:
+				v.properties[key] = values[0]
+			}
+		}
+		return v, len(v.properties) > 0, nil
+	}
+	if !found || len(values) == 0 {
+		return v, false, nil
+	}
+	switch kind {
+	case paramKindArray:
+		if ps.explode {
+			v.values = values
+			return v, true, nil
+		}
+		sep := ","
+		switch ps.style {
+		case openapi3.SerializationSpaceDelimited:
+			sep = " "
+		case openapi3.SerializationPipeDelimited:
+			sep = "|"
+		}
+		v.values = strings.Split(values[0], sep)
+	case paramKindObject:
+		v.properties, err = decodePairs(strings.Split(values[0], ","))
+	default:
+		v.values = values[:1]
+	}
+	return v, true, err
+}
+
+// decodeDelimited decodes a path, header or cookie parameter, e.g. the value ;id=3,4 of a
+// path parameter named id in the matrix style.
+func decodeDelimited(s, name string, ps paramStyle, kind paramKind) (v paramValue, err error) {
+	v.kind = kind
+	sep := ","
+	switch ps.style {
+	case openapi3.SerializationLabel:
+		s = strings.TrimPrefix(s, ".")
+		if ps.explode {
+			sep = "."
+		}
+	case openapi3.SerializationMatrix:
+		s = strings.TrimPrefix(s, ";")
+		if ps.explode && kind != paramKindPrimitive {
+			sep = ";"
+		} else {
+			s = strings.TrimPrefix(s, name+"=")
+		}
+	}
+	switch kind {
+	case paramKindArray:
+		v.values = strings.Split(s, sep)
+		if ps.style == openapi3.SerializationMatrix && ps.explode {
+			for i, value := range v.values {
+				v.values[i] = strings.TrimPrefix(value, name+"=")
+			}
+		}
+	case paramKindObject:
+		parts := strings.Split(s, sep)
+		if !ps.explode {
+			v.properties, err = decodePairs(parts)
+			return v, err
+		}
+		v.properties = make(map[string]string, len(parts))
+		for _, part := range parts {
+			key, value, ok := strings.Cut(part, "=")
+			if !ok {
+				return v, fmt.Errorf("value %q is not a list of key=value pairs", s)
+			}
+			v.properties[key] = value
+		}
+	default:
+		v.values = []string{s}
+	}
+	return v, nil
+}
+
+// decodePairs decodes the properties of an object that's sent as a list of keys and values,
+// e.g. status,open,limit,10.
+func decodePairs(parts []string) (map[string]string, error) {
+	if len(parts)%2 != 0 {
+		return nil, fmt.Errorf("value %q is not a list of keys and values", strings.Join(parts, ","))
+	}
+	op := make(map[string]string, len(parts)/2)
+	for i := 0; i < len(parts); i += 2 {
+		op[parts[i]] = parts[i+1]
+	}
+	return op, nil
+}
+
+// objectFields returns the fields of a struct by the name of the property in its JSON schema,
+// or nil if the type isn't a struct.
+func objectFields(t reflect.Type) map[string][]int {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	op := make(map[string][]int)
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		op[name] = f.Index
+	}
+	return op
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// set the field to the value, allocating pointers as required.
+func (pv paramValue) set(v reflect.Value) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return pv.set(v.Elem())
+	}
+	switch pv.kind {
+	case paramKindArray:
+		s := reflect.MakeSlice(reflect.SliceOf(v.Type().Elem()), len(pv.values), len(pv.values))
+		for i, value := range pv.values {
+			if err := setParamValue(s.Index(i), value); err != nil {
+				return err
+			}
+		}
+		if v.Kind() == reflect.Array {
+			if s.Len() != v.Len() {
+				return fmt.Errorf("expected %d values, got %d", v.Len(), s.Len())
+			}
+			reflect.Copy(v, s)
+			return nil
+		}
+		v.Set(s)
+	case paramKindObject:
+		if v.Kind() == reflect.Map {
+			m := reflect.MakeMapWithSize(v.Type(), len(pv.properties))
+			for key, value := range pv.properties {
+				elem := reflect.New(v.Type().Elem()).Elem()
+				if err := setParamValue(elem, value); err != nil {
+					return fmt.Errorf("property %q: %w", key, err)
+				}
+				m.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+			}
+			v.Set(m)
+			return nil
+		}
+		fields := objectFields(v.Type())
+		for _, key := range getSortedKeys(pv.properties) {
+			index, ok := fields[key]
+			if !ok {
+				return fmt.Errorf("property %q is not known", key)
+			}
+			if err := (paramValue{values: []string{pv.properties[key]}}).set(fieldByIndex(v, index)); err != nil {
+				return fmt.Errorf("property %q: %w", key, err)
+			}
+		}
+	default:
+		return setParamValue(v, pv.values[0])
+	}
+	return nil
+}
+
+func setParamValue(v reflect.Value, value string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setParamValue(v.Elem(), value)
+	}
+	if tu, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := tu.UnmarshalText([]byte(value)); err != nil {
+			return fmt.Errorf("value %q is not valid: %w", value, err)
+		}
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("value %q is not a boolean", value)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("value %q is not an integer", value)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("value %q is not an unsigned integer", value)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("value %q is not a number", value)
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported parameter type %v", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package openapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/go-cmp/cmp"
+)
+
+type ParamsStatus string
+
+func (ParamsStatus) ApplyCustomSchema(s *openapi3.Schema) {
+	s.Enum = []any{"open", "closed"}
+}
+
+type Pagination struct {
+	// Limit is the maximum number of results.
+	Limit *int `query:"limit"`
+}
+
+type ListTopicsParams struct {
+	Pagination
+	// Namespace of the topics.
+	Namespace string         `path:"namespace"`
+	Status    []ParamsStatus `query:"status,omitempty"`
+	Since     time.Time      `query:"since,omitempty"`
+	Tenant    string         `header:"X-Tenant"`
+	Session   string         `cookie:"session,omitempty"`
+	Ignored   string
+}
+
+func TestParamsOf(t *testing.T) {
+	api := NewAPI("topics")
+	api.Get("/topics/{namespace}").
+		HasParams(ParamsOf[ListTopicsParams]()).
+		HasResponseModel(http.StatusOK, ModelOf[[]string]())
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	params := spec.Paths.Find("/topics/{namespace}").Get.Parameters
+	if len(params) != 6 {
+		t.Errorf("expected 6 parameters, got %d", len(params))
+	}
+	tests := []struct {
+		in, name    string
+		required    bool
+		schemaType  string
+		ref         string
+		description string
+	}{
+		{in: "path", name: "namespace", required: true, schemaType: "string"},
+		{in: "query", name: "limit", schemaType: "integer"},
+		{in: "query", name: "status", schemaType: "array"},
+		{in: "query", name: "since", schemaType: "string"},
+		{in: "header", name: "X-Tenant", required: true, schemaType: "string"},
+		{in: "cookie", name: "session", schemaType: "string"},
+	}
+	for _, test := range tests {
+		p := params.GetByInAndName(test.in, test.name)
+		if p == nil {
+			t.Errorf("%s parameter %q is missing", test.in, test.name)
+			continue
+		}
+		if p.Required != test.required {
+			t.Errorf("%s parameter %q: expected required %v, got %v", test.in, test.name, test.required, p.Required)
+		}
+		if !p.Schema.Value.Type.Is(test.schemaType) {
+			t.Errorf("%s parameter %q: expected type %q, got %v", test.in, test.name, test.schemaType, p.Schema.Value.Type)
+		}
+	}
+	if d := params.GetByInAndName("query", "limit").Description; d != "Limit is the maximum number of results." {
+		t.Errorf("expected the limit description to be the field comment, got %q", d)
+	}
+	status := params.GetByInAndName("query", "status").Schema.Value
+	if status.Items.Ref == "" {
+		t.Errorf("expected the status items to reference the enum, got %+v", status.Items.Value)
+	}
+	if since := params.GetByInAndName("query", "since").Schema.Value; since.Format != "date-time" {
+		t.Errorf("expected since to have the date-time format, got %q", since.Format)
+	}
+}
+
+func TestBindParams(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		header        http.Header
+		expected      ListTopicsParams
+		expectedError []ValidationError
+	}{
+		{
+			name:   "all parameters are bound",
+			url:    "/topics/ns?limit=10&status=open&status=closed&since=2024-01-02T03:04:05Z",
+			header: http.Header{"X-Tenant": {"acme"}, "Cookie": {"session=abc"}},
+			expected: ListTopicsParams{
+				Pagination: Pagination{Limit: intPtr(10)},
+				Namespace:  "ns",
+				Status:     []ParamsStatus{"open", "closed"},
+				Since:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				Tenant:     "acme",
+				Session:    "abc",
+			},
+		},
+		{
+			name:   "missing and invalid parameters are reported",
+			url:    "/topics/ns?limit=ten",
+			header: http.Header{},
+			expectedError: []ValidationError{
+				{In: "query", Name: "limit", Detail: `value "ten" is not an integer`},
+				{In: "header", Name: "X-Tenant", Detail: "required parameter is missing"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var actual ListTopicsParams
+			var err error
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /topics/{namespace}", func(w http.ResponseWriter, r *http.Request) {
+				actual, err = BindParams[ListTopicsParams](r)
+			})
+			r := httptest.NewRequest(http.MethodGet, test.url, nil)
+			r.Header = test.header
+			mux.ServeHTTP(httptest.NewRecorder(), r)
+			if test.expectedError != nil {
+				var p *ProblemDetails
+				if !errors.As(err, &p) {
+					t.Fatalf("expected problem details, got %v", err)
+				}
+				if diff := cmp.Diff(test.expectedError, p.Extensions["errors"]); diff != "" {
+					t.Error(diff)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+type StyleFilter struct {
+	Status string `json:"status"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+type StyledParams struct {
+	IDs    []int             `path:"ids,style=label,explode=true"`
+	Coords StyleFilter       `path:"coords,style=matrix,explode=true"`
+	Tags   []string          `query:"tags,omitempty"`
+	Sizes  []int             `query:"sizes,style=pipeDelimited,explode=false,omitempty"`
+	Filter *StyleFilter      `query:"filter,style=deepObject,omitempty"`
+	Sort   map[string]string `query:"sort,explode=false,omitempty"`
+	Next   string            `query:"next,allowReserved,omitempty"`
+	Accept []string          `header:"X-Accept,omitempty"`
+	Pref   StyleFilter       `header:"X-Pref,explode=true,omitempty"`
+}
+
+func TestBindParamsStyles(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		header        http.Header
+		expected      StyledParams
+		expectedError []ValidationError
+	}{
+		{
+			name:   "parameters are decoded using their style",
+			url:    "/items/.1.2.3/;status=open;limit=3?tags=a&tags=b&sizes=1%7C2&filter%5Bstatus%5D=closed&filter%5Blimit%5D=5&sort=name,asc&next=/items?page=2",
+			header: http.Header{"X-Accept": {"a,b"}, "X-Pref": {"status=new,limit=1"}},
+			expected: StyledParams{
+				IDs:    []int{1, 2, 3},
+				Coords: StyleFilter{Status: "open", Limit: 3},
+				Tags:   []string{"a", "b"},
+				Sizes:  []int{1, 2},
+				Filter: &StyleFilter{Status: "closed", Limit: 5},
+				Sort:   map[string]string{"name": "asc"},
+				Next:   "/items?page=2",
+				Accept: []string{"a", "b"},
+				Pref:   StyleFilter{Status: "new", Limit: 1},
+			},
+		},
+		{
+			name:   "invalid objects are reported",
+			url:    "/items/.1/;status=open?sort=name&filter%5Bcolour%5D=red",
+			header: http.Header{"X-Pref": {"status"}},
+			expectedError: []ValidationError{
+				{In: "query", Name: "filter", Detail: `property "colour" is not known`},
+				{In: "query", Name: "sort", Detail: `value "name" is not a list of keys and values`},
+				{In: "header", Name: "X-Pref", Detail: `value "status" is not a list of key=value pairs`},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var actual StyledParams
+			var err error
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /items/{ids}/{coords}", func(w http.ResponseWriter, r *http.Request) {
+				actual, err = BindParams[StyledParams](r)
+			})
+			r := httptest.NewRequest(http.MethodGet, test.url, nil)
+			r.Header = test.header
+			mux.ServeHTTP(httptest.NewRecorder(), r)
+			if test.expectedError != nil {
+				var p *ProblemDetails
+				if !errors.As(err, &p) {
+					t.Fatalf("expected problem details, got %v", err)
+				}
+				if diff := cmp.Diff(test.expectedError, p.Extensions["errors"]); diff != "" {
+					t.Error(diff)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestParamSerialization(t *testing.T) {
+	tests := []struct {
+		in              string
+		style           string
+		explode         *bool
+		expectedStyle   string
+		expectedExplode *bool
+	}{
+		{in: "query"},
+		{in: "query", style: "form"},
+		{in: "query", explode: boolPtr(false), expectedExplode: boolPtr(false)},
+		{in: "query", style: "deepObject", expectedStyle: "deepObject", expectedExplode: boolPtr(true)},
+		{in: "query", style: "pipeDelimited", expectedStyle: "pipeDelimited"},
+		{in: "path", style: "simple"},
+		{in: "path", style: "label", explode: boolPtr(true), expectedStyle: "label", expectedExplode: boolPtr(true)},
+		{in: "header", explode: boolPtr(true), expectedExplode: boolPtr(true)},
+		{in: "cookie", explode: boolPtr(true)},
+	}
+	for _, test := range tests {
+		style, explode := paramSerialization(test.in, test.style, test.explode)
+		if style != test.expectedStyle || !cmp.Equal(explode, test.expectedExplode) {
+			t.Errorf("%s %q: expected %q %v, got %q %v", test.in, test.style, test.expectedStyle, test.expectedExplode, style, explode)
+		}
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,134 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pathTemplate is a route pattern, split into the path that's documented, and its parameters.
+type pathTemplate struct {
+	// path with routers' regular expressions removed, e.g. /users/{id:[0-9]+} becomes /users/{id}.
+	path string
+	// key is the path with the names of the parameters removed, e.g. /users/{}, so that
+	// paths that only differ by the names of their parameters can be found.
+	key    string
+	params []pathTemplateParam
+}
+
+type pathTemplateParam struct {
+	name string
+	// regexp the parameter must match, e.g. [0-9]+ for {id:[0-9]+}.
+	regexp string
+}
+
+// parsePathTemplate parses a route pattern, including the regular expressions used by routers
+// such as chi and gorilla/mux, e.g. /users/{id:[0-9]+}.
+func parsePathTemplate(pattern string) (t pathTemplate, err error) {
+	var path, key strings.Builder
+	seen := map[string]bool{}
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '{' {
+			path.WriteByte(pattern[i])
+			key.WriteByte(pattern[i])
+			continue
+		}
+		// Find the matching brace, since regular expressions may contain braces, e.g. {id:[0-9]{4}}.
+		depth, end := 0, -1
+		for j := i; j < len(pattern) && end < 0; j++ {
+			switch pattern[j] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth == 0 {
+					end = j
+				}
+			}
+		}
+		if end < 0 {
+			return t, fmt.Errorf("unterminated path parameter in %q", pattern)
+		}
+		name, regexp, _ := strings.Cut(pattern[i+1:end], ":")
+		if name == "" {
+			return t, fmt.Errorf("path parameter without a name in %q", pattern)
+		}
+		if seen[name] {
+			return t, fmt.Errorf("path parameter %q is used more than once in %q", name, pattern)
+		}
+		seen[name] = true
+		t.params = append(t.params, pathTemplateParam{name: name, regexp: regexp})
+		path.WriteString("{" + name + "}")
+		key.WriteString("{}")
+		i = end
+	}
+	t.path, t.key = path.String(), key.String()
+	return t, nil
+}
+
+// PathParams returns the path parameters of the route. Parameters that are in the pattern, but
+// haven't been declared with HasPathParameter, are inferred as string parameters, using the
+// regular expression of the pattern, if it has one, e.g. /users/{id:[0-9]+}.
+//
+// An error is returned if a declared parameter isn't in the pattern.
+func (rm *Route) PathParams() (map[string]PathParam, error) {
+	t, err := parsePathTemplate(string(rm.Pattern))
+	if err != nil {
+		return nil, err
+	}
+	return rm.pathParams(t)
+}
+
+func (rm *Route) pathParams(t pathTemplate) (map[string]PathParam, error) {
+	op := make(map[string]PathParam, len(t.params))
+	for _, p := range t.params {
+		v, ok := rm.Params.Path[p.name]
+		if !ok {
+			v = PathParam{Type: PrimitiveTypeString}
+		}
+		if v.Regexp == "" && p.regexp != "" && v.Model.Type == nil {
+			v.Regexp = "^" + p.regexp + "$"
+		}
+		op[p.name] = v
+	}
+	for _, name := range getSortedKeys(rm.Params.Path) {
+		if _, ok := op[name]; !ok {
+			return nil, fmt.Errorf("path parameter %q is not in the pattern", name)
+		}
+	}
+	return op, nil
+}
+
+// parsePathTemplates parses the patterns of the routes, and returns a problem for each pattern that
+// isn't valid, or that only differs from another by the names or regular expressions of their
+// parameters, e.g. /users/{id} and /users/{userID}, since they can't both be documented.
+func (api *API) parsePathTemplates() (map[Pattern]pathTemplate, SpecErrors) {
+	patterns := getSortedKeys(api.Routes)
+	op := make(map[Pattern]pathTemplate, len(patterns))
+	keyToPattern := make(map[string]Pattern, len(patterns))
+	var errs SpecErrors
+	for _, pattern := range patterns {
+		method := firstMethod(api.Routes[pattern])
+		t, err := parsePathTemplate(string(pattern))
+		if err != nil {
+			errs = append(errs, &SpecError{Method: method, Pattern: pattern, Err: err})
+			continue
+		}
+		if existing, ok := keyToPattern[t.key]; ok {
+			errs = append(errs, &SpecError{Method: method, Pattern: pattern, Err: fmt.Errorf("path conflicts with %s, since they only differ by their parameters", existing)})
+			continue
+		}
+		keyToPattern[t.key] = pattern
+		op[pattern] = t
+	}
+	return op, errs
+}
+
+// firstMethod returns the first of the methods in alphabetical order, for use in error messages.
+func firstMethod(methodToRoute MethodToRoute) (op Method) {
+	for method := range methodToRoute {
+		if op == "" || method < op {
+			op = method
+		}
+	}
+	return op
+}
@@ -0,0 +1,144 @@
+package openapi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParsePathTemplate(t *testing.T) {
+	tests := []struct {
+		pattern        string
+		expectedPath   string
+		expectedKey    string
+		expectedParams []pathTemplateParam
+		expectedErr    string
+	}{
+		{
+			pattern:      "/users",
+			expectedPath: "/users",
+			expectedKey:  "/users",
+		},
+		{
+			pattern:        "/users/{id}/posts/{postID}",
+			expectedPath:   "/users/{id}/posts/{postID}",
+			expectedKey:    "/users/{}/posts/{}",
+			expectedParams: []pathTemplateParam{{name: "id"}, {name: "postID"}},
+		},
+		{
+			pattern:        "/users/{id:[0-9]{4}}",
+			expectedPath:   "/users/{id}",
+			expectedKey:    "/users/{}",
+			expectedParams: []pathTemplateParam{{name: "id", regexp: "[0-9]{4}"}},
+		},
+		{
+			pattern:     "/users/{id",
+			expectedErr: `unterminated path parameter in "/users/{id"`,
+		},
+		{
+			pattern:     "/users/{}",
+			expectedErr: `path parameter without a name in "/users/{}"`,
+		},
+		{
+			pattern:     "/users/{id}/friends/{id}",
+			expectedErr: `path parameter "id" is used more than once in "/users/{id}/friends/{id}"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			actual, err := parsePathTemplate(test.pattern)
+			if test.expectedErr != "" {
+				if err == nil || err.Error() != test.expectedErr {
+					t.Fatalf("expected error %q, got %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual.path != test.expectedPath {
+				t.Errorf("expected path %q, got %q", test.expectedPath, actual.path)
+			}
+			if actual.key != test.expectedKey {
+				t.Errorf("expected key %q, got %q", test.expectedKey, actual.key)
+			}
+			if diff := cmp.Diff(test.expectedParams, actual.params, cmp.AllowUnexported(pathTemplateParam{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestPathParamsAreInferred(t *testing.T) {
+	api := NewAPI("users")
+	api.Get("/users/{id:[0-9]+}/posts/{postID}").
+		HasPathParameter("postID", PathParam{Description: "The ID of the post.", Type: PrimitiveTypeInteger}).
+		HasResponseModel(http.StatusOK, ModelOf[string]())
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	get := spec.Paths.Find("/users/{id}/posts/{postID}").Get
+	if get == nil {
+		t.Fatal("expected GET /users/{id}/posts/{postID} to be documented")
+	}
+	id := get.Parameters.GetByInAndName("path", "id")
+	if id == nil {
+		t.Fatal("expected the id parameter to be inferred")
+	}
+	if id.Schema.Value.Pattern != "^[0-9]+$" {
+		t.Errorf("expected the id parameter to use the pattern of the route, got %q", id.Schema.Value.Pattern)
+	}
+	postID := get.Parameters.GetByInAndName("path", "postID")
+	if postID == nil || postID.Description != "The ID of the post." {
+		t.Errorf("expected the postID parameter to keep its description, got %+v", postID)
+	}
+}
+
+func TestPathParamErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(api *API)
+		expectedErr string
+	}{
+		{
+			name: "declared parameters must be in the pattern",
+			setup: func(api *API) {
+				api.Get("/users/{id}").
+					HasPathParameter("userID", PathParam{}).
+					HasResponseModel(http.StatusOK, ModelOf[string]())
+			},
+			expectedErr: `GET /users/{id}: path parameter "userID" is not in the pattern`,
+		},
+		{
+			name: "paths can't only differ by parameter names",
+			setup: func(api *API) {
+				api.Get("/users/{id}").HasResponseModel(http.StatusOK, ModelOf[string]())
+				api.Delete("/users/{userID}").HasResponseModel(http.StatusOK, ModelOf[string]())
+			},
+			expectedErr: "DELETE /users/{userID}: path conflicts with /users/{id}, since they only differ by their parameters",
+		},
+		{
+			name: "patterns must be valid",
+			setup: func(api *API) {
+				api.Post("/users/{id").HasResponseModel(http.StatusOK, ModelOf[string]())
+			},
+			expectedErr: `POST /users/{id: unterminated path parameter in "/users/{id"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			api := NewAPI("users")
+			test.setup(api)
+			_, err := api.Spec()
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), test.expectedErr) {
+				t.Errorf("expected error to contain %q, got %q", test.expectedErr, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ContentTypeProblemJSON is the media type of an RFC 9457 problem details object.
+const ContentTypeProblemJSON = "application/problem+json"
+
+// ProblemDetails is an RFC 9457 problem details object, used to carry
+// machine-readable details of errors in HTTP responses.
+type ProblemDetails struct {
+	// Type is a URI reference that identifies the problem type.
+	// When empty, "about:blank" is assumed.
+	Type string `json:"type,omitempty"`
+	// Title is a short, human-readable summary of the problem type.
+	Title string `json:"title,omitempty"`
+	// Status is the HTTP status code generated by the origin server.
+	Status int `json:"status,omitempty"`
+	// Detail is a human-readable explanation specific to this occurrence of the problem.
+	Detail string `json:"detail,omitempty"`
+	// Instance is a URI reference that identifies the specific occurrence of the problem.
+	Instance string `json:"instance,omitempty"`
+	// Extensions are additional members of the problem details object.
+	// They're written alongside the standard members.
+	Extensions map[string]any `json:"-"`
+}
+
+// ApplyCustomSchema allows the extension members of the problem details object, and
+// documents its purpose.
+func (ProblemDetails) ApplyCustomSchema(s *openapi3.Schema) {
+	s.Description = "An RFC 9457 problem details object. Additional members may be present."
+	s.AdditionalProperties = openapi3.AdditionalProperties{
+		Has: boolPtr(true),
+	}
+}
+
+// NewProblemDetails creates a problem details object for the HTTP status code.
+// The title is set to the status text.
+func NewProblemDetails(status int, detail string) *ProblemDetails {
+	return &ProblemDetails{
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+func (p *ProblemDetails) Error() string {
+	if p.Detail != "" {
+		return fmt.Sprintf("%d %s: %s", p.Status, p.Title, p.Detail)
+	}
+	return fmt.Sprintf("%d %s", p.Status, p.Title)
+}
+
+// problemDetails is used to marshal the standard members without recursion.
+type problemDetails ProblemDetails
+
+func (p ProblemDetails) MarshalJSON() ([]byte, error) {
+	if len(p.Extensions) == 0 {
+		return json.Marshal(problemDetails(p))
+	}
+	m := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		m[k] = v
+	}
+	standard, err := json.Marshal(problemDetails(p))
+	if err != nil {
+		return nil, err
+	}
+	var sm map[string]any
+	if err = json.Unmarshal(standard, &sm); err != nil {
+		return nil, err
+	}
+	for k, v := range sm {
+		m[k] = v
+	}
+	return json.Marshal(m)
+}
+
+func (p *ProblemDetails) UnmarshalJSON(data []byte) error {
+	var pd problemDetails
+	if err := json.Unmarshal(data, &pd); err != nil {
+		return err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	for _, k := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(m, k)
+	}
+	if len(m) > 0 {
+		pd.Extensions = m
+	}
+	*p = ProblemDetails(pd)
+	return nil
+}
+
+// WriteProblem writes the problem details to the response, using the
+// application/problem+json content type and the status of the problem.
+func WriteProblem(w http.ResponseWriter, p *ProblemDetails) {
+	status := p.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", ContentTypeProblemJSON)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,110 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// SchemaProvider is a type that creates its own OpenAPI schema, instead of it being created
+// from its fields. The registry creates references to the schemas of other types. It's called
+// while the API is locked, so it must use the registry, rather than the methods of the API.
+// Example:
+//
+//	func (Shape) OpenAPISchema(reg *openapi.Registry) *openapi3.SchemaRef {
+//		return openapi3.NewOneOfSchema(
+//			reg.Ref(openapi.ModelOf[Circle]()).Value,
+//			reg.Ref(openapi.ModelOf[Square]()).Value,
+//		).NewRef()
+//	}
+type SchemaProvider interface {
+	OpenAPISchema(reg *Registry) *openapi3.SchemaRef
+}
+
+// SchemaNamer is a type that sets the name of its component in the OpenAPI specification.
+type SchemaNamer interface {
+	OpenAPIName() string
+}
+
+// FieldSchemaApplier is a type that customises the schemas of its fields. It's called with the
+// JSON name of each field, after the schema of the field has been created.
+type FieldSchemaApplier interface {
+	OpenAPIFieldSchema(field string, s *openapi3.Schema)
+}
+
+// Registry registers the models used by the schema of a SchemaProvider.
+type Registry struct {
+	api *API
+	err error
+}
+
+// Ref registers the model, and returns a reference to its component, or its schema, if it
+// isn't a component. The schema of the component is set as the value of the reference.
+func (reg *Registry) Ref(model Model) *openapi3.SchemaRef {
+	name, schema, err := reg.api.registerModel(model)
+	if err != nil {
+		reg.err = errors.Join(reg.err, err)
+		return openapi3.NewSchemaRef("", openapi3.NewSchema())
+	}
+	ref := getSchemaReferenceOrValue(name, schema)
+	ref.Value = schema
+	return ref
+}
+
+// implementationOf returns a value of t, or a pointer to t, that implements I, so that the methods
+// of value and pointer receivers are found in the same way. Pointers use the methods of their
+// element type, since their schema is the schema of the element.
+func implementationOf[I any](t reflect.Type) (v I, ok bool) {
+	if t == nil || t.Kind() == reflect.Pointer {
+		return v, false
+	}
+	v, ok = reflect.New(t).Interface().(I)
+	return v, ok
+}
+
+// providedSchema returns the schema created by the OpenAPISchema method of p, and the name of the
+// component it references, if it's a reference.
+func (api *API) providedSchema(p SchemaProvider) (name string, schema *openapi3.Schema, err error) {
+	reg := &Registry{api: api}
+	ref := p.OpenAPISchema(reg)
+	if reg.err != nil {
+		return name, schema, reg.err
+	}
+	if ref == nil {
+		return name, schema, errors.New("OpenAPISchema returned a nil schema")
+	}
+	if ref.Ref != "" {
+		name = strings.TrimPrefix(ref.Ref, "#/components/schemas/")
+		if schema = ref.Value; schema == nil {
+			schema = api.models[name]
+		}
+		if schema == nil {
+			return name, schema, fmt.Errorf("OpenAPISchema returned a reference to an unknown schema %q", ref.Ref)
+		}
+		return name, schema, nil
+	}
+	if ref.Value == nil {
+		return name, schema, errors.New("OpenAPISchema returned a nil schema")
+	}
+	return name, ref.Value, nil
+}
+
+// customiseProperty customises the schema of a property. The schema is copied, since it can be
+// shared with the embedded struct it came from, and referenced schemas are shared with other
+// properties, so they're wrapped in allOf, if they're customised.
+func customiseProperty(ref *openapi3.SchemaRef, customise func(s *openapi3.Schema)) *openapi3.SchemaRef {
+	if ref.Value != nil {
+		s := *ref.Value
+		customise(&s)
+		return s.NewRef()
+	}
+	wrapped := &openapi3.Schema{AllOf: openapi3.SchemaRefs{ref}}
+	customise(wrapped)
+	if reflect.DeepEqual(wrapped, &openapi3.Schema{AllOf: openapi3.SchemaRefs{ref}}) {
+		return ref
+	}
+	return wrapped.NewRef()
+}
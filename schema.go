@@ -1,14 +1,17 @@
 package openapi
 
 import (
+	"context"
+	"encoding"
+	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
 	"regexp"
 	"slices"
 	"sort"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"golang.org/x/exp/constraints"
@@ -17,7 +20,7 @@ import (
 	"github.com/ihezebin/openapi/getcomments/parser"
 )
 
-func newSpec(name string, info openapi3.Info, servers []openapi3.Server) *openapi3.T {
+func newSpec(name string, info openapi3.Info, servers []openapi3.Server, securitySchemes openapi3.SecuritySchemes) *openapi3.T {
 	if info.Title == "" {
 		info.Title = name
 	}
@@ -35,15 +38,16 @@ func newSpec(name string, info openapi3.Info, servers []openapi3.Server) *openap
 		Info:    &info,
 		Servers: servers3,
 		Components: &openapi3.Components{
-			Schemas:    make(openapi3.Schemas),
-			Extensions: map[string]interface{}{},
+			Schemas:         make(openapi3.Schemas),
+			SecuritySchemes: securitySchemes,
+			Extensions:      map[string]interface{}{},
 		},
 		Paths:      &openapi3.Paths{},
 		Extensions: map[string]interface{}{},
 	}
 }
 
-func getSortedKeys[V any](m map[string]V) (op []string) {
+func getSortedKeys[K ~string | ~int, V any](m map[K]V) (op []K) {
 	for k := range m {
 		op = append(op, k)
 	}
@@ -72,25 +76,94 @@ func newPrimitiveSchema(paramType PrimitiveType) *openapi3.Schema {
 	}
 }
 
+// newIntegerSchema returns the schema of an integer kind, with the int32 or int64 format that can
+// hold its values. Types that are smaller than 32 bits have minimum and maximum values, and
+// unsigned types have a minimum of zero. If the API encodes 64-bit integers as strings, int,
+// uint, int64 and uint64 are strings.
+func (api *API) newIntegerSchema(kind reflect.Kind) *openapi3.Schema {
+	bounds := func(s *openapi3.Schema, min, max float64) *openapi3.Schema {
+		return s.WithMin(min).WithMax(max)
+	}
+	switch kind {
+	case reflect.Int8:
+		return bounds(openapi3.NewInt32Schema(), math.MinInt8, math.MaxInt8)
+	case reflect.Int16:
+		return bounds(openapi3.NewInt32Schema(), math.MinInt16, math.MaxInt16)
+	case reflect.Int32:
+		return openapi3.NewInt32Schema()
+	case reflect.Uint8:
+		return bounds(openapi3.NewInt32Schema(), 0, math.MaxUint8)
+	case reflect.Uint16:
+		return bounds(openapi3.NewInt32Schema(), 0, math.MaxUint16)
+	case reflect.Uint32:
+		return bounds(openapi3.NewInt64Schema(), 0, math.MaxUint32)
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		if api.Int64AsString {
+			return stringEncoded(openapi3.NewInt64Schema().WithMin(0))
+		}
+		return openapi3.NewInt64Schema().WithMin(0)
+	}
+	if api.Int64AsString {
+		return stringEncoded(openapi3.NewInt64Schema())
+	}
+	return openapi3.NewInt64Schema()
+}
+
+// stringEncoded returns the schema of a number or boolean that's encoded as a JSON string, e.g.
+// "123" for an int64 field with the json:",string" tag. The format of the number is kept, and
+// the pattern matches the encoded value.
+func stringEncoded(s *openapi3.Schema) *openapi3.Schema {
+	op := openapi3.NewStringSchema()
+	op.Description, op.Deprecated, op.Nullable, op.Format = s.Description, s.Deprecated, s.Nullable, s.Format
+	switch {
+	case s.Type.Is(openapi3.TypeInteger) && s.Min != nil && *s.Min >= 0:
+		op.Pattern = "^[0-9]+$"
+	case s.Type.Is(openapi3.TypeInteger):
+		op.Pattern = "^-?[0-9]+$"
+	case s.Type.Is(openapi3.TypeBoolean):
+		op.Enum = []any{"true", "false"}
+	}
+	return op
+}
+
 func (api *API) createOpenAPI() (spec *openapi3.T, err error) {
-	spec = newSpec(api.Name, api.Info, api.Servers)
-	// Add all the routes.
-	for pattern, methodToRoute := range api.Routes {
+	spec = newSpec(api.Name, api.Info, api.Servers, api.SecuritySchemes)
+	// Problems are collected, so that they can all be fixed at once.
+	templates, errs := api.parsePathTemplates()
+	if len(errs) > 0 {
+		return spec, errs
+	}
+	var ops []specOperation
+	// Add all the routes, in order, so that anonymous types are named the same way each time.
+	for _, pattern := range getSortedKeys(api.Routes) {
+		methodToRoute := api.Routes[pattern]
 		path := &openapi3.PathItem{}
-		for method, route := range methodToRoute {
+		for _, method := range getSortedKeys(methodToRoute) {
+			route := methodToRoute[method]
 			op := &openapi3.Operation{}
+			pathParams, err := route.pathParams(templates[pattern])
+			if err != nil {
+				errs = append(errs, &SpecError{Method: method, Pattern: pattern, Err: err})
+			}
 
 			// Add the query params.
 			for _, k := range getSortedKeys(route.Params.Query) {
 				v := route.Params.Query[k]
 
-				ps := newPrimitiveSchema(v.Type).
-					WithPattern(v.Regexp)
-				queryParam := openapi3.NewQueryParameter(k).
-					WithDescription(v.Description).
-					WithSchema(ps)
+				ps, err := api.paramSchema(v.Type, v.Model, v.Regexp)
+				if err != nil {
+					errs = append(errs, newParamError(method, pattern, "query", k, v.Model.Type, err))
+					continue
+				}
+				queryParam := openapi3.NewQueryParameter(k)
+				queryParam.Schema = ps
 				queryParam.Required = v.Required
 				queryParam.AllowEmptyValue = v.AllowEmpty
+				queryParam.AllowReserved = v.AllowReserved
+				queryParam.Style, queryParam.Explode = paramSerialization(openapi3.ParameterInQuery, v.Style, v.Explode)
+				if err = api.documentParam(queryParam, v.Description, v.field); err != nil {
+					errs = append(errs, newParamError(method, pattern, "query", k, nil, err))
+				}
 
 				// Apply schema customisation.
 				if v.ApplyCustomSchema != nil {
@@ -101,14 +174,20 @@ func (api *API) createOpenAPI() (spec *openapi3.T, err error) {
 			}
 
 			// Add the route params.
-			for _, k := range getSortedKeys(route.Params.Path) {
-				v := route.Params.Path[k]
+			for _, k := range getSortedKeys(pathParams) {
+				v := pathParams[k]
 
-				ps := newPrimitiveSchema(v.Type).
-					WithPattern(v.Regexp)
-				pathParam := openapi3.NewPathParameter(k).
-					WithDescription(v.Description).
-					WithSchema(ps)
+				ps, err := api.paramSchema(v.Type, v.Model, v.Regexp)
+				if err != nil {
+					errs = append(errs, newParamError(method, pattern, "path", k, v.Model.Type, err))
+					continue
+				}
+				pathParam := openapi3.NewPathParameter(k)
+				pathParam.Schema = ps
+				pathParam.Style, pathParam.Explode = paramSerialization(openapi3.ParameterInPath, v.Style, v.Explode)
+				if err = api.documentParam(pathParam, v.Description, v.field); err != nil {
+					errs = append(errs, newParamError(method, pattern, "path", k, nil, err))
+				}
 
 				// Apply schema customisation.
 				if v.ApplyCustomSchema != nil {
@@ -122,11 +201,18 @@ func (api *API) createOpenAPI() (spec *openapi3.T, err error) {
 			for _, k := range getSortedKeys(route.Params.Header) {
 				v := route.Params.Header[k]
 
-				ps := newPrimitiveSchema(v.Type)
-				headerParam := openapi3.NewHeaderParameter(k).
-					WithDescription(v.Description).
-					WithSchema(ps)
+				ps, err := api.paramSchema(v.Type, v.Model, v.Regexp)
+				if err != nil {
+					errs = append(errs, newParamError(method, pattern, "header", k, v.Model.Type, err))
+					continue
+				}
+				headerParam := openapi3.NewHeaderParameter(k)
+				headerParam.Schema = ps
+				headerParam.Style, headerParam.Explode = paramSerialization(openapi3.ParameterInHeader, "", v.Explode)
 				headerParam.Required = v.Required
+				if err = api.documentParam(headerParam, v.Description, v.field); err != nil {
+					errs = append(errs, newParamError(method, pattern, "header", k, nil, err))
+				}
 
 				if v.ApplyCustomSchema != nil {
 					v.ApplyCustomSchema(headerParam)
@@ -135,44 +221,125 @@ func (api *API) createOpenAPI() (spec *openapi3.T, err error) {
 				op.AddParameter(headerParam)
 			}
 
-			// Handle request types.
-			if route.Models.Request.Type != nil {
-				name, schema, err := api.RegisterModel(route.Models.Request)
+			// Add the cookie params.
+			for _, k := range getSortedKeys(route.Params.Cookie) {
+				v := route.Params.Cookie[k]
+
+				ps, err := api.paramSchema(v.Type, v.Model, v.Regexp)
 				if err != nil {
-					return spec, err
+					errs = append(errs, newParamError(method, pattern, "cookie", k, v.Model.Type, err))
+					continue
+				}
+				cookieParam := openapi3.NewCookieParameter(k)
+				cookieParam.Schema = ps
+				cookieParam.Style, cookieParam.Explode = paramSerialization(openapi3.ParameterInCookie, "", v.Explode)
+				cookieParam.Required = v.Required
+				if err = api.documentParam(cookieParam, v.Description, v.field); err != nil {
+					errs = append(errs, newParamError(method, pattern, "cookie", k, nil, err))
+				}
+
+				if v.ApplyCustomSchema != nil {
+					v.ApplyCustomSchema(cookieParam)
 				}
-				op.RequestBody = &openapi3.RequestBodyRef{
-					Value: openapi3.NewRequestBody().WithContent(map[string]*openapi3.MediaType{
-						"application/json": {
-							Schema: getSchemaReferenceOrValue(name, schema),
-						},
-					}),
+
+				op.AddParameter(cookieParam)
+			}
+
+			// Handle request types. The request model is JSON, unless other content is declared.
+			requestContent := make(map[string]Model, len(route.Models.RequestContent)+1)
+			if route.Models.Request.Type != nil {
+				requestContent["application/json"] = route.Models.Request
+			}
+			for mediaType, model := range route.Models.RequestContent {
+				requestContent[mediaType] = model
+			}
+			if len(requestContent) > 0 {
+				body := openapi3.NewRequestBody().WithContent(make(openapi3.Content))
+				for _, mediaType := range getSortedKeys(requestContent) {
+					model := requestContent[mediaType]
+					mt, err := api.mediaType(mediaType, model, route.Models.RequestEncoding[mediaType])
+					if err != nil {
+						fieldPath, err := splitFieldError(model.Type, err)
+						errs = append(errs, &SpecError{Method: method, Pattern: pattern, FieldPath: fieldPath, Err: fmt.Errorf("request model: %w", err)})
+						continue
+					}
+					body.Content[mediaType] = mt
 				}
+				op.RequestBody = &openapi3.RequestBodyRef{Value: body}
 			}
 
-			// Handle response types.
+			// Handle response types, including the default responses of the API.
+			responses := make(map[int]map[string]Model)
+			addResponse := func(status int, mediaType string, model Model) {
+				if responses[status] == nil {
+					responses[status] = make(map[string]Model)
+				}
+				responses[status][mediaType] = model
+			}
 			for status, model := range route.Models.Responses {
-				name, schema, err := api.RegisterModel(model)
-				if err != nil {
-					return spec, err
+				// Responses without a model, e.g. 204 No Content, have no content.
+				if model.Type == nil {
+					if responses[status] == nil {
+						responses[status] = make(map[string]Model)
+					}
+					continue
+				}
+				addResponse(status, responseContentType(model.Type), model)
+			}
+			for status, content := range route.Models.ResponseContent {
+				for mediaType, model := range content {
+					addResponse(status, mediaType, model)
 				}
+			}
+			for status := range route.Models.ResponseEvents {
+				if responses[status] == nil {
+					responses[status] = make(map[string]Model)
+				}
+			}
+			for status, model := range api.DefaultResponses {
+				if _, ok := responses[status]; !ok {
+					addResponse(status, responseContentType(model.Type), model)
+				}
+			}
+			for _, status := range getSortedKeys(responses) {
 				resp := openapi3.NewResponse().
 					WithDescription("").
-					WithContent(map[string]*openapi3.MediaType{
-						"application/json": {
-							Schema: getSchemaReferenceOrValue(name, schema),
-						},
-					})
+					WithContent(make(openapi3.Content))
+				for _, mediaType := range getSortedKeys(responses[status]) {
+					model := responses[status][mediaType]
+					mt, err := api.mediaType(mediaType, model, nil)
+					if err != nil {
+						fieldPath, err := splitFieldError(model.Type, err)
+						errs = append(errs, &SpecError{Method: method, Pattern: pattern, Status: status, FieldPath: fieldPath, Err: err})
+						continue
+					}
+					resp.Content[mediaType] = mt
+				}
+				if events := route.Models.ResponseEvents[status]; len(events) > 0 {
+					mt, failed, err := api.eventStreamMediaType(events)
+					if err != nil {
+						fieldPath, err := splitFieldError(failed.Model.Type, err)
+						errs = append(errs, &SpecError{Method: method, Pattern: pattern, Status: status, FieldPath: fieldPath, Err: fmt.Errorf("event %q: %w", failed.eventName(), err)})
+					} else {
+						resp.Content[ContentTypeEventStream] = mt
+					}
+				}
 
-				// 添加响应头处理
+				// Add the response headers, which have the same schemas as header parameters.
 				if headers, exists := route.Models.ResponseHeaders[status]; exists {
 					headerSchemas := make(map[string]*openapi3.HeaderRef)
-					for name, param := range headers {
-						schema := newPrimitiveSchema(param.Type)
+					for _, name := range getSortedKeys(headers) {
+						param := headers[name]
+						schema, err := api.paramSchema(param.Type, param.Model, param.Regexp)
+						if err != nil {
+							fieldPath, err := splitFieldError(param.Model.Type, err)
+							errs = append(errs, &SpecError{Method: method, Pattern: pattern, Status: status, FieldPath: fieldPath, Err: fmt.Errorf("response header %q: %w", name, err)})
+							continue
+						}
 						headerSchemas[name] = &openapi3.HeaderRef{
 							Value: &openapi3.Header{
 								Parameter: openapi3.Parameter{
-									Schema:      schema.NewRef(),
+									Schema:      schema,
 									Required:    param.Required,
 									Description: param.Description,
 								},
@@ -182,6 +349,25 @@ func (api *API) createOpenAPI() (spec *openapi3.T, err error) {
 					resp.Headers = headerSchemas
 				}
 
+				// Examples are added to the content of the response model, or else the first content.
+				if examples, exists := route.Models.ResponseExamples[status]; exists && len(resp.Content) > 0 {
+					mt := resp.Content.Get(getSortedKeys(resp.Content)[0])
+					if model, ok := route.Models.Responses[status]; ok && resp.Content.Get(responseContentType(model.Type)) != nil {
+						mt = resp.Content.Get(responseContentType(model.Type))
+					}
+					mt.Examples = make(openapi3.Examples)
+					for name, value := range examples {
+						value, err := toJSONValue(value)
+						if err != nil {
+							errs = append(errs, &SpecError{Method: method, Pattern: pattern, Status: status, Err: fmt.Errorf("invalid example %q: %w", name, err)})
+							continue
+						}
+						mt.Examples[name] = &openapi3.ExampleRef{
+							Value: openapi3.NewExample(value),
+						}
+					}
+				}
+
 				op.AddResponse(status, resp)
 			}
 
@@ -199,33 +385,208 @@ func (api *API) createOpenAPI() (spec *openapi3.T, err error) {
 
 			// Register the method.
 			path.SetOperation(string(method), op)
+			ops = append(ops, specOperation{method: method, pattern: pattern, op: op})
 
 			// Handle deprecated.
 			op.Deprecated = route.Deprecated
+
+			// Handle security.
+			if len(route.Security) > 0 {
+				security := slices.Clone(route.Security)
+				op.Security = &security
+			}
 		}
 
-		// Populate the OpenAPI schemas from the models.
-		for name, schema := range api.models {
-			spec.Components.Schemas[name] = openapi3.NewSchemaRef("", schema)
+		spec.Paths.Set(templates[pattern].path, path)
+	}
+
+	// Populate the OpenAPI schemas from the models.
+	for name, schema := range api.models {
+		spec.Components.Schemas[name] = openapi3.NewSchemaRef("", schema)
+	}
+	if api.DirectionalModels {
+		registered := make(map[string]bool)
+		for _, r := range api.registrations {
+			registered[api.getModelName(r.model.Type)] = true
 		}
+		errs = append(errs, splitDirectionalModels(spec, registered)...)
+	}
 
-		spec.Paths.Set(string(pattern), path)
+	if len(errs) > 0 {
+		errs.sort()
+		return spec, errs
 	}
 
 	loader := openapi3.NewLoader()
 	if err = loader.ResolveRefsIn(spec, nil); err != nil {
-		return spec, fmt.Errorf("failed to resolve, due to external references: %w", err)
+		return spec, SpecErrors{{Err: fmt.Errorf("failed to resolve, due to external references: %w", err)}}
+	}
+	for _, op := range ops {
+		errs = append(errs, op.validate(loader.Context)...)
 	}
+	if len(errs) > 0 {
+		errs.sort()
+		return spec, errs
+	}
+	// Check the rest of the spec, e.g. models that aren't used by any route.
 	if err = spec.Validate(loader.Context); err != nil {
-		return spec, fmt.Errorf("failed validation: %w", err)
+		return spec, SpecErrors{{Err: fmt.Errorf("failed validation: %w", err)}}
+	}
+
+	return spec, nil
+}
+
+// mediaType creates the content of a request or response for the media type. XML media types
+// use the xml tags of the model, form media types use its form tags, and other media types use
+// its json tags, except for binary content, e.g. a []byte sent as application/octet-stream. If
+// the model has no type, the content has no schema.
+//
+// The encoding describes the parts of a form, by the name of the property.
+func (api *API) mediaType(mediaType string, model Model, encoding map[string]*openapi3.Encoding) (*openapi3.MediaType, error) {
+	mt := openapi3.NewMediaType()
+	if model.Type == nil {
+		return mt, nil
+	}
+	var name string
+	var schema *openapi3.Schema
+	var err error
+	switch {
+	case isXMLMediaType(mediaType):
+		name, schema, err = api.registerXMLModel(model)
+	case isFormMediaType(mediaType):
+		name, schema, err = api.registerFormModel(mediaType, model)
+	case !isJSONMediaType(mediaType) && isBinaryType(model.Type):
+		// Binary content, e.g. a file download, is sent as it is.
+		schema = newBinarySchema()
+	default:
+		name, schema, err = api.registerModel(model)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, property := range getSortedKeys(encoding) {
+		if _, ok := schema.Properties[property]; !ok {
+			return nil, fmt.Errorf("encoding of %q: the property is not in the model", property)
+		}
+		if mt.Encoding == nil {
+			mt.Encoding = make(map[string]*openapi3.Encoding)
+		}
+		mt.Encoding[property] = encoding[property]
+	}
+	return mt.WithSchemaRef(getSchemaReferenceOrValue(name, schema)), nil
+}
+
+// responseContentType returns the media type of a response model. Problem details are
+// documented as application/problem+json, and other models as application/json.
+func responseContentType(t reflect.Type) string {
+	if t == reflect.TypeFor[ProblemDetails]() || t == reflect.TypeFor[*ProblemDetails]() {
+		return ContentTypeProblemJSON
+	}
+	return "application/json"
+}
+
+// specOperation is an operation of the spec, and the route it was created from.
+type specOperation struct {
+	method  Method
+	pattern Pattern
+	op      *openapi3.Operation
+}
+
+// validate the operation, checking each response separately, so that problems can be
+// reported with their status code.
+func (so specOperation) validate(ctx context.Context) (errs SpecErrors) {
+	for _, code := range getSortedKeys(so.op.Responses.Map()) {
+		if err := so.op.Responses.Value(code).Validate(ctx); err != nil {
+			status, _ := strconv.Atoi(code)
+			errs = append(errs, &SpecError{Method: so.method, Pattern: so.pattern, Status: status, Err: err})
+		}
+	}
+	if so.op.RequestBody != nil {
+		if err := so.op.RequestBody.Validate(ctx); err != nil {
+			errs = append(errs, &SpecError{Method: so.method, Pattern: so.pattern, Err: fmt.Errorf("request body: %w", err)})
+		}
 	}
+	if err := so.op.Parameters.Validate(ctx); err != nil {
+		errs = append(errs, &SpecError{Method: so.method, Pattern: so.pattern, Err: err})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	if err := so.op.Validate(ctx); err != nil {
+		errs = append(errs, &SpecError{Method: so.method, Pattern: so.pattern, Err: err})
+	}
+	return errs
+}
 
-	return spec, err
+// newParamError creates a problem with a parameter. If the problem is in the schema of the model
+// of the parameter, the path to the field is included.
+func newParamError(method Method, pattern Pattern, in, name string, model reflect.Type, err error) *SpecError {
+	fieldPath, err := splitFieldError(model, err)
+	return &SpecError{Method: method, Pattern: pattern, FieldPath: fieldPath, Err: fmt.Errorf("%s parameter %q: %w", in, name, err)}
+}
+
+// paramSchema returns the schema of a parameter, which is created from the model if it's set.
+func (api *API) paramSchema(paramType PrimitiveType, model Model, pattern string) (*openapi3.SchemaRef, error) {
+	if model.Type == nil {
+		return newPrimitiveSchema(paramType).WithPattern(pattern).NewRef(), nil
+	}
+	name, schema, err := api.registerModel(model)
+	if err != nil {
+		return nil, err
+	}
+	ref := getSchemaReferenceOrValue(name, schema)
+	if ref.Value != nil && pattern != "" {
+		ref.Value.Pattern = pattern
+	}
+	return ref, nil
+}
+
+// paramSerialization returns the style and explode values to document for a parameter, which
+// are left out when they're the defaults of the location. The deepObject style is always exploded.
+func paramSerialization(in, style string, explode *bool) (string, *bool) {
+	ps := newParamStyle(in, style, explode)
+	style = ps.style
+	if style == newParamStyle(in, "", nil).style {
+		style = ""
+	}
+	// When explode isn't set, it's true for the form style, and false for other styles.
+	if ps.explode == (ps.style == openapi3.SerializationForm) {
+		return style, nil
+	}
+	return style, &ps.explode
+}
+
+// documentParam sets the description of the parameter. If it's empty, the comment
+// of the struct field that the parameter was created from is used. The constraints of the
+// validate tag of the field are added to the schema of the parameter.
+func (api *API) documentParam(p *openapi3.Parameter, description string, f *paramField) (err error) {
+	p.Description = description
+	if f != nil && f.validate != "" && p.Schema != nil && p.Schema.Value != nil {
+		value := *p.Schema.Value
+		if err = applyValidateTag(&value, f.validate); err != nil {
+			return err
+		}
+		p.Schema = value.NewRef()
+	}
+	if description != "" || f == nil {
+		return nil
+	}
+	if p.Description, p.Deprecated, err = api.getTypeFieldComment(f.pkgPath, f.typeName, f.name); err != nil {
+		return fmt.Errorf("failed to get comments for field %q in type %q: %w", f.name, f.typeName, err)
+	}
+	return nil
 }
 
 func (api *API) getModelName(t reflect.Type) string {
+	// Types can name themselves.
+	if n, ok := implementationOf[SchemaNamer](t); ok {
+		return n.OpenAPIName()
+	}
 	pkgPath, typeName := t.PkgPath(), t.Name()
 	if t.Kind() == reflect.Pointer {
+		if n, ok := implementationOf[SchemaNamer](t.Elem()); ok {
+			return n.OpenAPIName() + "Ptr"
+		}
 		pkgPath = t.Elem().PkgPath()
 		typeName = t.Elem().Name() + "Ptr"
 	}
@@ -234,17 +595,32 @@ func (api *API) getModelName(t reflect.Type) string {
 	}
 
 	if t.Kind() == reflect.Struct && typeName == "" {
-		typeName = "Struct_" + strconv.FormatInt(time.Now().UnixMicro(), 10)
-
+		return "AnonymousType" + strconv.Itoa(api.anonymousIndex(t))
+	}
+	// Unnamed slices are named after their elements, so that they don't share a name with other unnamed types.
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && typeName == "" {
+		return "ArrayOf" + api.getModelName(t.Elem())
 	}
 
-	schemaName := api.normalizeTypeName(pkgPath, typeName)
+	schemaName := api.normalizeTypeName(t, pkgPath, typeName)
 	if typeName == "" {
 		schemaName = fmt.Sprintf("AnonymousType")
 	}
 	return schemaName
 }
 
+// anonymousIndex returns the index used in the name of an anonymous type, or of a generic type
+// with an anonymous type argument. Indexes are assigned in the order the types are registered,
+// so that the names are the same each time the spec is created.
+func (api *API) anonymousIndex(t reflect.Type) int {
+	index, ok := api.anonymousTypes[t]
+	if !ok {
+		index = len(api.anonymousTypes)
+		api.anonymousTypes[t] = index
+	}
+	return index
+}
+
 func getSchemaReferenceOrValue(name string, schema *openapi3.Schema) *openapi3.SchemaRef {
 	if shouldBeReferenced(schema) {
 		return openapi3.NewSchemaRef(fmt.Sprintf("#/components/schemas/%s", name), nil)
@@ -318,8 +694,39 @@ func isMarkedAsDeprecated(comment string) bool {
 }
 
 // RegisterModel allows a model to be registered manually so that additional configuration can be applied.
-// The schema returned can be modified as required.
+// The schema returned can be modified as required, before the spec is first created, since the
+// cached spec isn't invalidated when it's changed. If the models are created again, since
+// Customize or RegisterKnownType was called after the spec was created, changes to the
+// returned schema are lost, so use opts for changes that must be kept.
 func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, schema *openapi3.Schema, err error) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.invalidate()
+	if name, schema, err = api.registerModel(model, opts...); err != nil {
+		return name, schema, err
+	}
+	api.registrations = append(api.registrations, registration{model: model, opts: opts})
+	return name, schema, nil
+}
+
+// resetModels discards the models that have been created, if their schemas have changed since,
+// and registers the models registered with RegisterModel again. The caller must hold api.mu.
+func (api *API) resetModels() error {
+	if !api.rebuildModels {
+		return nil
+	}
+	api.rebuildModels = false
+	api.models = make(map[string]*openapi3.Schema)
+	api.modelTypes = make(map[string]reflect.Type)
+	for _, r := range api.registrations {
+		if _, _, err := api.registerModel(r.model, r.opts...); err != nil {
+			return fmt.Errorf("failed to register model %v: %w", r.model.Type, err)
+		}
+	}
+	return nil
+}
+
+func (api *API) registerModel(model Model, opts ...ModelOpts) (name string, schema *openapi3.Schema, err error) {
 	// Get the name.
 	t := model.Type
 	name = api.getModelName(t)
@@ -330,23 +737,61 @@ func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, sche
 		return name, schema, nil
 	}
 
+	// The type creates its own schema.
+	if p, ok := implementationOf[SchemaProvider](t); ok {
+		refName, providedSchema, err := api.providedSchema(p)
+		if err != nil {
+			return name, schema, fmt.Errorf("failed to get the schema of %v: %w", t, err)
+		}
+		// It's another component.
+		if refName != "" {
+			return refName, providedSchema, nil
+		}
+		if shouldBeReferenced(providedSchema) {
+			api.models[name] = providedSchema
+			api.modelTypes[name] = t
+		}
+		return name, providedSchema, nil
+	}
+
 	// It's known, but not in the schemaset yet.
-	if knownSchema, ok := api.KnownTypes[t]; ok {
+	if knownSchema, ok := api.KnownType(t); ok {
 		// Objects, enums, need to be references, so add it into the
 		// list.
 		if shouldBeReferenced(&knownSchema) {
 			api.models[name] = &knownSchema
+			api.modelTypes[name] = t
 		}
 		return name, &knownSchema, nil
 	}
 
+	kind := t.Kind()
+	if kind != reflect.Pointer && kind != reflect.Interface {
+		switch {
+		case implements(t, textMarshalerType):
+			// TextMarshalers are encoded as JSON strings. Types that also implement json.Marshaler,
+			// such as decimals, are assumed to be encoded as strings too.
+			kind = reflect.String
+		case implements(t, jsonMarshalerType):
+			// The fields of the type don't describe its JSON, so the schema must be customised.
+			if model.s == nil {
+				return name, schema, fmt.Errorf("%v implements json.Marshaler, so its schema can't be created from its fields: add it to KnownTypes, or implement ApplyCustomSchema or OpenAPISchema", t)
+			}
+		}
+	}
+
 	var elementName string
 	var elementSchema *openapi3.Schema
-	switch t.Kind() {
+	switch kind {
 	case reflect.Slice, reflect.Array:
-		elementName, elementSchema, err = api.RegisterModel(modelFromType(t.Elem()))
+		// Byte slices are encoded as base64 strings.
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			schema = openapi3.NewBytesSchema().WithNullable()
+			break
+		}
+		elementName, elementSchema, err = api.registerModel(modelFromType(t.Elem()))
 		if err != nil {
-			return name, schema, fmt.Errorf("error getting schema of slice element %v: %w", t.Elem(), err)
+			return name, schema, withFieldPath("[]", err)
 		}
 		schema = openapi3.NewArraySchema().WithNullable() // Arrays are always nilable in Go.
 		schema.Items = getSchemaReferenceOrValue(elementName, elementSchema)
@@ -359,23 +804,33 @@ func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, sche
 	case reflect.String:
 		schema = openapi3.NewStringSchema()
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		schema = openapi3.NewIntegerSchema()
-	case reflect.Float64, reflect.Float32:
+		schema = api.newIntegerSchema(t.Kind())
+	case reflect.Float32:
+		schema = openapi3.NewFloat64Schema().WithFormat("float")
+	case reflect.Float64:
 		schema = openapi3.NewFloat64Schema()
 	case reflect.Bool:
 		schema = openapi3.NewBoolSchema()
 	case reflect.Pointer:
-		name, schema, err = api.RegisterModel(modelFromType(t.Elem()), WithNullable())
+		name, schema, err = api.registerModel(modelFromType(t.Elem()))
+		if err != nil {
+			return name, schema, err
+		}
+		// Referenced schemas are shared with the element type, so can't be marked as nullable.
+		if !shouldBeReferenced(schema) {
+			nullable := *schema
+			schema = nullable.WithNullable()
+		}
 	case reflect.Map:
-		// Check that the key is a string.
-		if t.Key().Kind() != reflect.String {
+		// Check that the key is encoded as a string.
+		if t.Key().Kind() != reflect.String && !implements(t.Key(), textMarshalerType) {
 			return name, schema, fmt.Errorf("maps must have a string key, but this map is of type %q", t.Key().String())
 		}
 
 		// Get the element schema.
-		elementName, elementSchema, err = api.RegisterModel(modelFromType(t.Elem()))
+		elementName, elementSchema, err = api.registerModel(modelFromType(t.Elem()))
 		if err != nil {
-			return name, schema, fmt.Errorf("error getting schema of map value element %v: %w", t.Elem(), err)
+			return name, schema, withFieldPath("{}", err)
 		}
 		schema = openapi3.NewObjectSchema().WithNullable()
 		schema.AdditionalProperties.Schema = getSchemaReferenceOrValue(elementName, elementSchema)
@@ -385,28 +840,30 @@ func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, sche
 		// 	return name, schema, fmt.Errorf("failed to get comments for type %q: %w", name, err)
 		// }
 		schema.Properties = make(openapi3.Schemas)
+		fieldApplier, _ := implementationOf[FieldSchemaApplier](t)
 		for i := 0; i < t.NumField(); i++ {
 			f := t.Field(i)
 			if !f.IsExported() {
 				continue
 			}
 			// Get JSON fieldName.
-			jsonTags := strings.Split(f.Tag.Get("json"), ",")
-			fieldName := jsonTags[0]
-			if fieldName == "" {
-				fieldName = f.Name
+			fieldName, encoded := jsonFieldName(f)
+			if !encoded {
+				continue
 			}
+			jsonTags := strings.Split(f.Tag.Get("json"), ",")
 			// If the model doesn't exist.
 			_, alreadyExists := api.models[api.getModelName(f.Type)]
-			fieldSchemaName, fieldSchema, err := api.RegisterModel(modelFromType(f.Type))
+			fieldSchemaName, fieldSchema, err := api.registerModel(modelFromType(f.Type))
 			if err != nil {
-				return name, schema, fmt.Errorf("error getting schema for type %q, field %q, failed to get schema for embedded type %q: %w", t, fieldName, f.Type, err)
+				return name, schema, withFieldPath("."+f.Name, err)
 			}
 			if f.Anonymous {
 				// It's an anonymous type, no need for a reference to it,
 				// since we're copying the fields.
 				if !alreadyExists {
 					delete(api.models, fieldSchemaName)
+					delete(api.modelTypes, fieldSchemaName)
 				}
 				// Add all embedded fields to this type.
 				for name, ref := range fieldSchema.Properties {
@@ -418,9 +875,23 @@ func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, sche
 			ref := getSchemaReferenceOrValue(fieldSchemaName, fieldSchema)
 			if ref.Value != nil {
 				if ref.Value.Description, ref.Value.Deprecated, err = api.getTypeFieldComment(t.PkgPath(), t.Name(), f.Name); err != nil {
-					return name, schema, fmt.Errorf("failed to get comments for field %q in type %q: %w", fieldName, name, err)
+					return name, schema, withFieldPath("."+f.Name, fmt.Errorf("failed to get comments: %w", err))
+				}
+				if tag, ok := f.Tag.Lookup("validate"); ok {
+					if err = applyValidateTag(ref.Value, tag); err != nil {
+						return name, schema, withFieldPath("."+f.Name, err)
+					}
 				}
 			}
+			// The string option encodes numbers and booleans as JSON strings.
+			if slices.Contains(jsonTags[1:], "string") && ref.Value != nil && (ref.Value.Type.Is(openapi3.TypeInteger) || ref.Value.Type.Is(openapi3.TypeNumber) || ref.Value.Type.Is(openapi3.TypeBoolean)) {
+				ref.Value = stringEncoded(ref.Value)
+			}
+			if fieldApplier != nil {
+				ref = customiseProperty(ref, func(s *openapi3.Schema) {
+					fieldApplier.OpenAPIFieldSchema(fieldName, s)
+				})
+			}
 			schema.Properties[fieldName] = ref
 			isPtr := f.Type.Kind() == reflect.Pointer
 			hasOmitEmptySet := slices.Contains(jsonTags, "omitempty")
@@ -428,10 +899,16 @@ func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, sche
 				schema.Required = append(schema.Required, fieldName)
 			}
 		}
+		// Apply the customisations of Customize, including to the fields of embedded structs.
+		for _, fs := range api.fieldSchemas[t] {
+			if ref, ok := schema.Properties[fs.name]; ok {
+				schema.Properties[fs.name] = customiseProperty(ref, fs.applyTo)
+			}
+		}
 	}
 
 	if schema == nil {
-		return name, schema, fmt.Errorf("unsupported type: %v/%v", t.PkgPath(), t.Name())
+		return name, schema, fmt.Errorf("unsupported type %v", t)
 	}
 
 	// Apply global customisation.
@@ -450,12 +927,39 @@ func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, sche
 	// After all processing, register the type if required.
 	if shouldBeReferenced(schema) {
 		api.models[name] = schema
+		// Pointers are registered with the name and type of their element.
+		if t.Kind() != reflect.Pointer {
+			api.modelTypes[name] = t
+		}
 		return
 	}
 
 	return
 }
 
+// RegisteredModel is a model that is included in the components of the OpenAPI specification.
+type RegisteredModel struct {
+	// Type of the model.
+	Type reflect.Type
+	// Schema of the model.
+	Schema *openapi3.Schema
+}
+
+// RegisteredModels returns the models that have been registered, either with RegisterModel, or
+// during the creation of the OpenAPI specification, by component name.
+func (api *API) RegisteredModels() map[string]RegisteredModel {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	op := make(map[string]RegisteredModel, len(api.models))
+	for name, schema := range api.models {
+		op[name] = RegisteredModel{
+			Type:   api.modelTypes[name],
+			Schema: schema,
+		}
+	}
+	return op
+}
+
 func (api *API) getCommentsForPackage(pkg string) (pkgComments map[string]string, err error) {
 	if pkgComments, loaded := api.comments[pkg]; loaded {
 		return pkgComments, nil
@@ -493,6 +997,17 @@ func (api *API) getTypeFieldComment(pkg string, name string, field string) (comm
 	return
 }
 
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// implements returns true if t, or a pointer to t, implements the interface, since
+// encoding/json uses the methods of pointer receivers when the value is addressable.
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || t.Kind() != reflect.Pointer && reflect.PointerTo(t).Implements(iface)
+}
+
 func shouldBeReferenced(schema *openapi3.Schema) bool {
 	if schema.Type.Is(openapi3.TypeObject) && schema.AdditionalProperties.Schema == nil {
 		return true
@@ -518,7 +1033,7 @@ var normalizer = strings.NewReplacer(
 	"*", "",
 )
 
-func (api *API) normalizeTypeName(pkgPath, name string) string {
+func (api *API) normalizeTypeName(t reflect.Type, pkgPath, name string) string {
 	var omitPackage bool
 	for _, pkg := range api.StripPkgPaths {
 		if strings.HasPrefix(pkgPath, pkg) {
@@ -536,8 +1051,8 @@ func (api *API) normalizeTypeName(pkgPath, name string) string {
 	//先判断是否匹配，再替换
 	if re.MatchString(name) {
 		name = re.ReplaceAllString(name, "${1}[struct]")
-		// 加随机值
-		name = strings.ReplaceAll(name, "[struct]", "[struct_"+strconv.FormatInt(time.Now().UnixMicro(), 10)+"]")
+		// 加序号，以区分不同的匿名结构体
+		name = strings.ReplaceAll(name, "[struct]", "[struct_"+strconv.Itoa(api.anonymousIndex(t))+"]")
 	}
 
 	typeName := normalizer.Replace(pkgPath + "/" + name)
@@ -551,6 +1066,16 @@ func (api *API) normalizeTypeName(pkgPath, name string) string {
 	return typeName
 }
 
+// toJSONValue converts v into the maps, slices and primitive values it's represented by in JSON.
+func toJSONValue(v any) (op any, err error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &op)
+	return op, err
+}
+
 func boolPtr(b bool) *bool {
 	return &b
 }
@@ -1,11 +1,20 @@
 package openapi
 
 import (
+	"database/sql"
 	"embed"
 	_ "embed"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
+	"math/big"
+	"mime/multipart"
 	"net/http"
+	"net/mail"
+	"net/netip"
+	"net/url"
 	"reflect"
 	"sync"
 	"testing"
@@ -110,6 +119,239 @@ type KnownTypes struct {
 	TimePtr *time.Time `json:"timePtr"`
 }
 
+type ContentOrder struct {
+	XMLName xml.Name `xml:"order" json:"-"`
+	// ID of the order.
+	ID       string        `xml:"id,attr" json:"id"`
+	Items    []ContentItem `xml:"items>item" json:"items"`
+	Note     string        `xml:",chardata" json:"note"`
+	Customer *string       `xml:"customer,omitempty" json:"customer"`
+}
+
+type ContentItem struct {
+	SKU      string `xml:"sku" json:"sku"`
+	Quantity int    `xml:"qty" json:"quantity"`
+}
+
+type ContentNode struct {
+	XMLName  xml.Name      `xml:"node"`
+	Name     string        `xml:"name,attr"`
+	Children []ContentNode `xml:"children>node,omitempty"`
+	Parent   *ContentNode  `xml:"parent,omitempty"`
+}
+
+type FormUpload struct {
+	// Title of the document.
+	Title       string                  `form:"title"`
+	Tags        []string                `form:"tags,omitempty"`
+	Document    *multipart.FileHeader   `form:"document"`
+	Attachments []*multipart.FileHeader `form:"attachments,omitempty"`
+	Thumbnail   []byte                  `form:"thumbnail,omitempty"`
+	Ignored     string                  `form:"-"`
+}
+
+type FormLogin struct {
+	Username string `form:"username"`
+	Password string `form:"password"`
+	Remember bool   `form:"remember,omitempty"`
+}
+
+type FormDocument struct {
+	Title    string `json:"title"`
+	Checksum []byte `json:"checksum"`
+}
+
+type StreamCreated struct {
+	ID string `json:"id"`
+}
+
+type StreamShipped struct {
+	ID      string `json:"id"`
+	Carrier string `json:"carrier"`
+}
+
+type ParamTypesSearch struct {
+	// Page of results.
+	Page  int    `query:"page,omitempty" validate:"min=1"`
+	Query string `query:"q" validate:"max=100"`
+}
+
+type ParamTypesResult struct {
+	Name  string `json:"name" validate:"required,min=1,max=64"`
+	Score int    `json:"score" validate:"gte=0,lte=10"`
+}
+
+type NumberFormats struct {
+	ID       uint64  `json:"id"`
+	Count    int     `json:"count"`
+	Small    int8    `json:"small"`
+	Port     uint16  `json:"port"`
+	Ratio    float32 `json:"ratio"`
+	Version  int32   `json:"version,string"`
+	Enabled  bool    `json:"enabled,string"`
+	Balance  float64 `json:"balance,string"`
+	Children []int64 `json:"children"`
+}
+
+// MarshalerID is encoded as a hex string.
+type MarshalerID struct {
+	value [4]byte
+}
+
+func (id MarshalerID) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(id.value[:])), nil
+}
+
+// MarshalerMoney is encoded as a JSON string, e.g. "1.50".
+type MarshalerMoney struct {
+	cents int64
+}
+
+func (m MarshalerMoney) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`"%d.%02d"`, m.cents/100, m.cents%100)), nil
+}
+
+func (MarshalerMoney) ApplyCustomSchema(s *openapi3.Schema) {
+	*s = *openapi3.NewStringSchema().WithPattern(`^[0-9]+\.[0-9]{2}$`)
+}
+
+// MarshalerRate implements json.Marshaler and encoding.TextMarshaler, like decimal types, and is
+// encoded as a JSON string.
+type MarshalerRate struct {
+	percent int64
+}
+
+func (r MarshalerRate) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`"%d%%"`, r.percent)), nil
+}
+
+func (r MarshalerRate) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d%%", r.percent)), nil
+}
+
+type MarshalerAccount struct {
+	ID       MarshalerID           `json:"id"`
+	Address  netip.Addr            `json:"address"`
+	Balance  MarshalerMoney        `json:"balance"`
+	Rate     MarshalerRate         `json:"rate"`
+	Limits   map[MarshalerID]int   `json:"limits"`
+	Metadata json.RawMessage       `json:"metadata"`
+	Parent   *MarshalerID          `json:"parent"`
+	Aliases  map[netip.Addr]string `json:"aliases,omitempty"`
+}
+
+// KnownTypesUUID has the shape of github.com/google/uuid.UUID.
+type KnownTypesUUID [16]byte
+
+func (id KnownTypesUUID) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(id[:])), nil
+}
+
+// KnownTypesDecimal is registered with RegisterKnownType.
+type KnownTypesDecimal struct {
+	value *big.Rat
+}
+
+type KnownTypesRecord struct {
+	ID        KnownTypesUUID         `json:"id"`
+	Timeout   time.Duration          `json:"timeout"`
+	Homepage  url.URL                `json:"homepage"`
+	Email     mail.Address           `json:"email"`
+	Network   netip.Prefix           `json:"network"`
+	Count     big.Int                `json:"count"`
+	Name      sql.NullString         `json:"name"`
+	Age       sql.NullInt16          `json:"age"`
+	DeletedAt sql.NullTime           `json:"deletedAt"`
+	Score     sql.Null[float32]      `json:"score"`
+	Price     KnownTypesDecimal      `json:"price"`
+	Tags      map[string]json.Number `json:"tags,omitempty"`
+}
+
+type TypeMethodsCircle struct {
+	Radius float64 `json:"radius"`
+}
+
+type TypeMethodsSquare struct {
+	Side float64 `json:"side"`
+}
+
+// TypeMethodsShape is a circle or a square.
+type TypeMethodsShape struct {
+	value any
+}
+
+func (TypeMethodsShape) OpenAPISchema(reg *Registry) *openapi3.SchemaRef {
+	s := &openapi3.Schema{
+		OneOf: openapi3.SchemaRefs{
+			reg.Ref(ModelOf[TypeMethodsCircle]()),
+			reg.Ref(ModelOf[TypeMethodsSquare]()),
+		},
+	}
+	return s.NewRef()
+}
+
+type TypeMethodsAddress struct {
+	Line1 string `json:"line1"`
+}
+
+// TypeMethodsLevel has methods with pointer receivers.
+type TypeMethodsLevel string
+
+func (*TypeMethodsLevel) ApplyCustomSchema(s *openapi3.Schema) {
+	s.Enum = []any{"admin", "member"}
+}
+
+func (*TypeMethodsLevel) OpenAPIName() string {
+	return "Level"
+}
+
+type TypeMethodsUser struct {
+	Email    string             `json:"email"`
+	Level    TypeMethodsLevel   `json:"level"`
+	Home     TypeMethodsAddress `json:"home"`
+	Work     TypeMethodsAddress `json:"work"`
+	Avatar   TypeMethodsShape   `json:"avatar"`
+	Previous *TypeMethodsLevel  `json:"previous"`
+}
+
+func (TypeMethodsUser) OpenAPIName() string {
+	return "Person"
+}
+
+func (*TypeMethodsUser) OpenAPIFieldSchema(field string, s *openapi3.Schema) {
+	switch field {
+	case "email":
+		s.Format = "email"
+	case "work":
+		s.Description = "Address of the office."
+	}
+}
+
+type CustomizeAudit struct {
+	CreatedBy string `json:"createdBy"`
+}
+
+type CustomizeUser struct {
+	CustomizeAudit
+	Email   string             `json:"emailAddress"`
+	Age     int                `json:"age,omitempty"`
+	Home    TypeMethodsAddress `json:"home"`
+	Nick    string
+	Ignored string `json:"-"`
+}
+
+type DirectionalUser struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Password  string    `json:"password"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type DirectionalUsers struct {
+	Users []DirectionalUser `json:"users"`
+	Total int               `json:"total"`
+}
+
 type User struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
@@ -402,6 +644,201 @@ func TestSchema(t *testing.T) {
 				return
 			},
 		},
+		{
+			name: "default-responses.yaml",
+			opts: []APIOpts{
+				WithDefaultResponse(http.StatusInternalServerError, ModelOf[ProblemDetails]()),
+				WithDefaultResponse(StatusDefault, ModelOf[ProblemDetails]()),
+			},
+			setup: func(api *API) (err error) {
+				api.Get("/users").
+					HasResponseModel(http.StatusOK, ModelOf[[]User]())
+				api.Delete("/users/{id}").
+					HasResponseModel(http.StatusOK, ModelOf[OK]()).
+					HasResponseModel(http.StatusInternalServerError, ModelOf[map[string]string]())
+				return
+			},
+		},
+		{
+			name: "content-types.yaml",
+			setup: func(api *API) (err error) {
+				api.Get("/orders/{id}").
+					HasResponseModel(http.StatusOK, ModelOf[ContentOrder]()).
+					HasResponseContent(http.StatusOK, "application/xml", ModelOf[ContentOrder]())
+				api.Get("/orders/export").
+					HasResponseContent(http.StatusOK, "text/csv", NoSchema())
+				api.Patch("/orders/{id}").
+					HasRequestContent("application/merge-patch+json", ModelOf[ContentItem]()).
+					HasResponseContent(http.StatusOK, "application/vnd.orders.v2+json", ModelOf[ContentOrder]())
+				api.Post("/orders").
+					HasRequestModel(ModelOf[ContentOrder]()).
+					HasRequestContent("application/xml", ModelOf[ContentOrder]()).
+					HasResponseModel(http.StatusCreated, ModelOf[ContentOrder]())
+				return
+			},
+		},
+		{
+			name: "xml-recursive.yaml",
+			setup: func(api *API) (err error) {
+				api.Get("/nodes/{id}").
+					HasResponseContent(http.StatusOK, "application/xml", ModelOf[ContentNode]())
+				return
+			},
+		},
+		{
+			name: "forms.yaml",
+			setup: func(api *API) (err error) {
+				api.Post("/documents").
+					HasRequestContent(ContentTypeMultipartForm, ModelOf[FormUpload]()).
+					HasRequestEncoding(ContentTypeMultipartForm, "document", &openapi3.Encoding{
+						ContentType: "application/pdf",
+						Headers: openapi3.Headers{
+							"X-Checksum": &openapi3.HeaderRef{Value: &openapi3.Header{Parameter: openapi3.Parameter{Schema: openapi3.NewStringSchema().NewRef()}}},
+						},
+					}).
+					HasRequestEncoding(ContentTypeMultipartForm, "thumbnail", &openapi3.Encoding{ContentType: "image/png, image/jpeg"}).
+					HasResponseModel(http.StatusCreated, ModelOf[FormDocument]())
+				api.Post("/login").
+					HasRequestContent(ContentTypeURLEncodedForm, ModelOf[FormLogin]()).
+					HasResponseModel(http.StatusOK, ModelOf[OK]())
+				api.Get("/documents/{id}/download").
+					HasResponseContent(http.StatusOK, ContentTypeOctetStream, ModelOf[[]byte]()).
+					HasResponseContent(http.StatusOK, "image/png", ModelOf[io.Reader]())
+				return
+			},
+		},
+		{
+			name: "streams.yaml",
+			setup: func(api *API) (err error) {
+				api.Get("/orders/events").
+					HasEventStream(http.StatusOK,
+						EventOf[StreamCreated]("created"),
+						EventOf[StreamShipped]("shipped"),
+						EventOf[StreamShipped]("delivered"),
+						EventOf[string](""))
+				api.Get("/orders/{id}/events").
+					HasEventStream(http.StatusOK, EventOf[StreamShipped]("shipped"))
+				api.Get("/orders/export").
+					HasNDJSONStream(http.StatusOK, ModelOf[StreamCreated]())
+				return
+			},
+		},
+		{
+			name: "param-styles.yaml",
+			setup: func(api *API) (err error) {
+				api.Get("/items/{ids}/{coords}").
+					HasParams(ParamsOf[StyledParams]()).
+					HasHeaderParameter("X-Request-ID", HeaderParam{Regexp: "^[a-f0-9]{32}$"}).
+					HasCookieParameter("prefs", CookieParam{Model: ModelOf[[]string](), Explode: boolPtr(false)}).
+					HasResponseModel(http.StatusOK, ModelOf[StyledParams]())
+				return
+			},
+		},
+		{
+			name: "param-types.yaml",
+			setup: func(api *API) (err error) {
+				api.Get("/topics/{id}").
+					HasPathParameter("id", PathOf[int]("ID of the topic.")).
+					HasQueryParameter("status", QueryOf[[]ParamsStatus]("Statuses to include.")).
+					HasQueryParameter("since", QueryOf[time.Time]("")).
+					HasHeaderParameter("X-Status", HeaderOf[ParamsStatus]("Status of the client.")).
+					HasCookieParameter("session", CookieOf[string]("Session ID.")).
+					HasParams(ParamsOf[ParamTypesSearch]()).
+					HasResponseModel(http.StatusOK, ModelOf[ParamTypesResult]()).
+					HasResponseHeader(http.StatusOK, "Last-Modified", HeaderOf[time.Time]("When the topic was last modified.")).
+					HasResponseHeader(http.StatusOK, "X-Request-ID", HeaderParam{Description: "ID of the request.", Regexp: `^[a-f0-9]+$`})
+				return
+			},
+		},
+		{
+			name: "int64-as-string.yaml",
+			opts: []APIOpts{WithInt64AsString()},
+			setup: func(api *API) (err error) {
+				api.Get("/numbers").HasResponseModel(http.StatusOK, ModelOf[NumberFormats]())
+				return
+			},
+		},
+		{
+			name: "marshalers.yaml",
+			setup: func(api *API) (err error) {
+				api.Get("/accounts/{id}").
+					HasPathParameter("id", PathOf[MarshalerID]("ID of the account.")).
+					HasResponseModel(http.StatusOK, ModelOf[MarshalerAccount]())
+				return
+			},
+		},
+		{
+			name: "known-types-pack.yaml",
+			setup: func(api *API) (err error) {
+				RegisterKnownType[KnownTypesDecimal](api, *openapi3.NewStringSchema().WithPattern(`^-?[0-9]+(\.[0-9]+)?$`))
+				api.Get("/records").HasResponseModel(http.StatusOK, ModelOf[KnownTypesRecord]())
+				return
+			},
+		},
+		{
+			name: "type-methods.yaml",
+			setup: func(api *API) (err error) {
+				api.Get("/people/{level}").
+					HasPathParameter("level", PathOf[TypeMethodsLevel]("Level of the people.")).
+					HasResponseModel(http.StatusOK, ModelOf[[]TypeMethodsUser]())
+				return
+			},
+		},
+		{
+			name: "customize.yaml",
+			setup: func(api *API) (err error) {
+				Customize(api, func(f *Fields[CustomizeUser], u *CustomizeUser) {
+					f.Of(&u.Email).Format("email").Example("alice@example.com")
+					f.Of(&u.Age).Min(0).Max(150)
+					f.Of(&u.Home).Description("Home address.")
+					f.Of(&u.CreatedBy).Deprecated()
+					f.Of(&u.Nick).Pattern(`^[a-z]+$`)
+				})
+				api.Get("/users").HasResponseModel(http.StatusOK, ModelOf[CustomizeUser]())
+				api.Get("/audits").HasResponseModel(http.StatusOK, ModelOf[CustomizeAudit]())
+				return
+			},
+		},
+		{
+			name: "directional-models.yaml",
+			opts: []APIOpts{WithDirectionalModels()},
+			setup: func(api *API) (err error) {
+				Customize(api, func(f *Fields[DirectionalUser], u *DirectionalUser) {
+					f.Of(&u.ID).ReadOnly()
+					f.Of(&u.CreatedAt).ReadOnly()
+					f.Of(&u.Password).WriteOnly()
+				})
+				api.Post("/users").
+					HasRequestModel(ModelOf[DirectionalUser]()).
+					HasResponseModel(http.StatusCreated, ModelOf[DirectionalUser]())
+				api.Get("/users").HasResponseModel(http.StatusOK, ModelOf[DirectionalUsers]())
+				api.Get("/health").HasResponseModel(http.StatusOK, ModelOf[OK]())
+				return
+			},
+		},
+		{
+			name: "directional-registered.yaml",
+			opts: []APIOpts{WithDirectionalModels()},
+			setup: func(api *API) (err error) {
+				Customize(api, func(f *Fields[DirectionalUser], u *DirectionalUser) {
+					f.Of(&u.ID).ReadOnly()
+				})
+				_, _, err = api.RegisterModel(ModelOf[DirectionalUser]())
+				return
+			},
+		},
+		{
+			name: "directional-events.yaml",
+			opts: []APIOpts{WithDirectionalModels()},
+			setup: func(api *API) (err error) {
+				Customize(api, func(f *Fields[DirectionalUser], u *DirectionalUser) {
+					f.Of(&u.ID).ReadOnly()
+					f.Of(&u.Password).WriteOnly()
+				})
+				api.Get("/users/events").HasEventStream(http.StatusOK, EventOf[DirectionalUser]("user"))
+				return
+			},
+		},
 		{
 			name: "global-customisation.yaml",
 			opts: []APIOpts{
@@ -0,0 +1,44 @@
+package openapi
+
+import "net/http"
+
+// ServeMux is a http.ServeMux that documents each route that it registers in an API.
+//
+// Patterns use the syntax of http.ServeMux, e.g. "GET /files/{path...}", and must have a method,
+// since the route is documented for that method alone. The route is documented with the method
+// and path of the pattern, see Handle for how patterns are documented, and the wildcards of the
+// pattern are documented as path parameters.
+//
+// The embedded http.ServeMux can be used to register handlers without documenting them, e.g.
+// for patterns without a method. Typed handlers are registered with Handle, e.g.
+// openapi.Handle(mux, api, "GET /users/{id}", getUser).
+type ServeMux struct {
+	*http.ServeMux
+	api *API
+}
+
+// NewServeMux creates a ServeMux that documents its routes in the API.
+func NewServeMux(api *API) *ServeMux {
+	return &ServeMux{
+		ServeMux: http.NewServeMux(),
+		api:      api,
+	}
+}
+
+// Handle registers the handler for the pattern, and returns the route, so that it can be documented.
+// It panics if the pattern doesn't have a method.
+// Example:
+//
+//	mux.Handle("GET /users/{id}", getUserHandler).
+//		HasResponseModel(http.StatusOK, openapi.ModelOf[User]())
+func (m *ServeMux) Handle(pattern string, handler http.Handler) *Route {
+	requireMethod(pattern)
+	m.ServeMux.Handle(pattern, handler)
+	method, path := parsePattern(pattern)
+	return m.api.Route(method, path)
+}
+
+// HandleFunc registers the handler function for the pattern, and returns the route, so that it can be documented.
+func (m *ServeMux) HandleFunc(pattern string, handler func(w http.ResponseWriter, r *http.Request)) *Route {
+	return m.Handle(pattern, http.HandlerFunc(handler))
+}
@@ -0,0 +1,119 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		pattern        string
+		expectedMethod string
+		expectedPath   string
+	}{
+		{pattern: "GET /users/{id}", expectedMethod: http.MethodGet, expectedPath: "/users/{id}"},
+		{pattern: "POST\t/users", expectedMethod: http.MethodPost, expectedPath: "/users"},
+		{pattern: "/users", expectedMethod: "", expectedPath: "/users"},
+		{pattern: "GET /files/{path...}", expectedMethod: http.MethodGet, expectedPath: "/files/{path}"},
+		{pattern: "GET example.com/users/{id}", expectedMethod: http.MethodGet, expectedPath: "/users/{id}"},
+		{pattern: "example.com/", expectedMethod: "", expectedPath: "/"},
+		{pattern: "GET /{$}", expectedMethod: http.MethodGet, expectedPath: "/"},
+		{pattern: "GET /users/{$}", expectedMethod: http.MethodGet, expectedPath: "/users/"},
+	}
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			method, path := parsePattern(test.pattern)
+			if method != test.expectedMethod {
+				t.Errorf("expected method %q, got %q", test.expectedMethod, method)
+			}
+			if path != test.expectedPath {
+				t.Errorf("expected path %q, got %q", test.expectedPath, path)
+			}
+		})
+	}
+}
+
+func TestServeMux(t *testing.T) {
+	api := NewAPI("files")
+	mux := NewServeMux(api)
+	mux.HandleFunc("GET example.com/files/{bucket}/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.PathValue("bucket") + ":" + r.PathValue("path")))
+	}).
+		HasDescription("Download a file.").
+		HasPathParameter("bucket", PathParam{Description: "The bucket that contains the file."}).
+		HasResponseModel(http.StatusOK, ModelOf[string]())
+	mux.Handle("DELETE /files/{bucket}/{$}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})).
+		HasResponseModel(http.StatusNoContent, ModelOf[string]())
+
+	t.Run("requests are routed", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "http://example.com/files/docs/a/b.txt", nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		if diff := cmp.Diff("docs:a/b.txt", w.Body.String()); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("routes are documented", func(t *testing.T) {
+		spec, err := api.Spec()
+		if err != nil {
+			t.Fatalf("failed to create spec: %v", err)
+		}
+		get := spec.Paths.Find("/files/{bucket}/{path}").Get
+		if get == nil {
+			t.Fatal("expected GET /files/{bucket}/{path} to be documented")
+		}
+		if get.Description != "Download a file." {
+			t.Errorf("unexpected description %q", get.Description)
+		}
+		bucket := get.Parameters.GetByInAndName("path", "bucket")
+		if bucket == nil || bucket.Description != "The bucket that contains the file." {
+			t.Errorf("expected the bucket path parameter to keep its description, got %+v", bucket)
+		}
+		if get.Parameters.GetByInAndName("path", "path") == nil {
+			t.Error("expected the path parameter to be inferred from the wildcard")
+		}
+		del := spec.Paths.Find("/files/{bucket}/").Delete
+		if del == nil {
+			t.Fatal("expected DELETE /files/{bucket}/ to be documented")
+		}
+		if del.Parameters.GetByInAndName("path", "bucket") == nil {
+			t.Error("expected the bucket parameter to be inferred from the wildcard")
+		}
+	})
+}
+
+func TestServeMuxRequiresMethod(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a pattern without a method")
+		}
+	}()
+	NewServeMux(NewAPI("files")).HandleFunc("/files/", func(w http.ResponseWriter, r *http.Request) {})
+}
+
+func TestHandleServeMux(t *testing.T) {
+	api := NewAPI("users")
+	mux := NewServeMux(api)
+	Handle(mux, api, "GET /users/{id}", func(ctx context.Context, req GetUserRequest) (GetUserRequest, error) {
+		return req, nil
+	})
+	r := httptest.NewRequest(http.MethodGet, "/users/123", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	if spec.Paths.Find("/users/{id}").Get == nil {
+		t.Error("expected GET /users/{id} to be documented")
+	}
+}
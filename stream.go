@@ -0,0 +1,247 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	// ContentTypeEventStream is the media type of a Server-Sent Events stream.
+	ContentTypeEventStream = "text/event-stream"
+	// ContentTypeNDJSON is the media type of a stream of newline delimited JSON values.
+	ContentTypeNDJSON = "application/x-ndjson"
+)
+
+// Event is a named event of a Server-Sent Events stream, and the model of its data.
+type Event struct {
+	// Name of the event, sent in the event field. Events without a name are message events.
+	Name string
+	// Model of the data of the event, which is sent as JSON.
+	Model Model
+}
+
+// EventOf creates an event with data of type T.
+func EventOf[T any](name string) Event {
+	return Event{Name: name, Model: ModelOf[T]()}
+}
+
+// eventName returns the name of the event, which defaults to message.
+func (e Event) eventName() string {
+	if e.Name == "" {
+		return "message"
+	}
+	return e.Name
+}
+
+// HasEventStream documents a text/event-stream response for the status code, which sends the
+// events. The schema of the stream is one of the models of the events, and the x-events
+// extension maps the name of each event to its model.
+// Example:
+//
+//	api.Get("/orders/events").HasEventStream(http.StatusOK,
+//		openapi.EventOf[OrderCreated]("created"),
+//		openapi.EventOf[OrderShipped]("shipped"))
+func (rm *Route) HasEventStream(status int, events ...Event) *Route {
+	return rm.update(func() {
+		if rm.Models.ResponseEvents == nil {
+			rm.Models.ResponseEvents = make(map[int][]Event)
+		}
+		rm.Models.ResponseEvents[status] = append(rm.Models.ResponseEvents[status], events...)
+	})
+}
+
+// HasNDJSONStream documents an application/x-ndjson response for the status code, which is
+// a stream of JSON values of the item model, one per line.
+// Example:
+//
+//	api.Get("/orders/export").HasNDJSONStream(http.StatusOK, openapi.ModelOf[Order]())
+func (rm *Route) HasNDJSONStream(status int, item Model) *Route {
+	return rm.HasResponseContent(status, ContentTypeNDJSON, item)
+}
+
+// eventStreamMediaType creates the content of a text/event-stream response. If the model of an
+// event can't be registered, the event is returned with the error.
+func (api *API) eventStreamMediaType(events []Event) (mt *openapi3.MediaType, failed Event, err error) {
+	mt = openapi3.NewMediaType()
+	eventRefs := make(map[string]*openapi3.SchemaRef, len(events))
+	var oneOf openapi3.SchemaRefs
+	seen := make(map[string]bool)
+	for _, e := range events {
+		if e.Model.Type == nil {
+			eventRefs[e.eventName()] = openapi3.NewSchema().NewRef()
+			continue
+		}
+		name, schema, err := api.registerModel(e.Model)
+		if err != nil {
+			return nil, e, err
+		}
+		ref := getSchemaReferenceOrValue(name, schema)
+		eventRefs[e.eventName()] = ref
+		// Events that share a model are only included once.
+		if !seen[name] {
+			seen[name] = true
+			oneOf = append(oneOf, ref)
+		}
+	}
+	switch len(oneOf) {
+	case 0:
+	case 1:
+		mt.Schema = oneOf[0]
+	default:
+		mt.Schema = openapi3.NewOneOfSchema().NewRef()
+		mt.Schema.Value.OneOf = oneOf
+	}
+	mt.Extensions = map[string]any{"x-events": eventRefs}
+	return mt, Event{}, nil
+}
+
+// read calls f while holding the lock of the API, so that the route isn't changed while it's read.
+func (rm *Route) read(f func()) {
+	if rm.api == nil {
+		f()
+		return
+	}
+	rm.api.mu.Lock()
+	defer rm.api.mu.Unlock()
+	f()
+}
+
+// EventStream writes the events of a text/event-stream response.
+//
+// In builds with the openapidebug tag, events are checked against the events documented
+// with HasEventStream, and Send returns an error for events that aren't documented, or that
+// have data of a different type.
+type EventStream struct {
+	w      http.ResponseWriter
+	events map[string]Model
+}
+
+// NewEventStream writes the headers of a text/event-stream response for the status code of the
+// route, and returns a stream to send its events.
+// Example:
+//
+//	stream := openapi.NewEventStream(w, route, http.StatusOK)
+//	if err := stream.Send("created", OrderCreated{ID: "123"}); err != nil {
+//		return
+//	}
+func NewEventStream(w http.ResponseWriter, route *Route, status int) *EventStream {
+	s := &EventStream{w: w, events: make(map[string]Model)}
+	route.read(func() {
+		for _, e := range route.Models.ResponseEvents[status] {
+			s.events[e.eventName()] = e.Model
+		}
+	})
+	w.Header().Set("Content-Type", ContentTypeEventStream)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(status)
+	flush(w)
+	return s
+}
+
+// Send writes the event, with the data encoded as JSON, and flushes it to the client.
+// Events without a name are message events. Names can't contain line breaks, since they
+// would end the event field, and start another field or event.
+func (s *EventStream) Send(name string, data any) error {
+	if strings.ContainsAny(name, "\r\n") {
+		return fmt.Errorf("event name %q contains a line break", name)
+	}
+	if debugStreams {
+		e := Event{Name: name}
+		model, ok := s.events[e.eventName()]
+		if !ok {
+			return fmt.Errorf("event %q is not documented", e.eventName())
+		}
+		if err := checkModel(model, data); err != nil {
+			return fmt.Errorf("event %q: %w", e.eventName(), err)
+		}
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %q: %w", name, err)
+	}
+	var sb strings.Builder
+	if name != "" {
+		sb.WriteString("event: " + name + "\n")
+	}
+	sb.WriteString("data: " + string(b) + "\n\n")
+	if _, err = s.w.Write([]byte(sb.String())); err != nil {
+		return err
+	}
+	flush(s.w)
+	return nil
+}
+
+// NDJSONStream writes the values of an application/x-ndjson response.
+//
+// In builds with the openapidebug tag, values are checked against the item model documented
+// with HasNDJSONStream, and Send returns an error for values of a different type.
+type NDJSONStream struct {
+	w     http.ResponseWriter
+	item  Model
+	found bool
+}
+
+// NewNDJSONStream writes the headers of an application/x-ndjson response for the status code of
+// the route, and returns a stream to send its values.
+func NewNDJSONStream(w http.ResponseWriter, route *Route, status int) *NDJSONStream {
+	s := &NDJSONStream{w: w}
+	route.read(func() {
+		s.item, s.found = route.Models.ResponseContent[status][ContentTypeNDJSON]
+	})
+	w.Header().Set("Content-Type", ContentTypeNDJSON)
+	w.WriteHeader(status)
+	flush(w)
+	return s
+}
+
+// Send writes the value as a line of JSON, and flushes it to the client.
+func (s *NDJSONStream) Send(v any) error {
+	if debugStreams {
+		if !s.found {
+			return fmt.Errorf("the %s response is not documented", ContentTypeNDJSON)
+		}
+		if err := checkModel(s.item, v); err != nil {
+			return err
+		}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode value: %w", err)
+	}
+	if _, err = s.w.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	flush(s.w)
+	return nil
+}
+
+func flush(w http.ResponseWriter) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// checkModel returns an error if the value isn't of the type of the model, or a pointer to it.
+// Models without a type accept any value.
+func checkModel(model Model, v any) error {
+	t, vt := model.Type, reflect.TypeOf(v)
+	switch {
+	case t == nil:
+		return nil
+	case vt == nil:
+		if t.Kind() == reflect.Pointer || t.Kind() == reflect.Interface || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+			return nil
+		}
+	case vt == t,
+		vt.Kind() == reflect.Pointer && vt.Elem() == t,
+		t.Kind() == reflect.Pointer && t.Elem() == vt,
+		t.Kind() == reflect.Interface && vt.Implements(t):
+		return nil
+	}
+	return fmt.Errorf("expected data of type %v, got %v", t, vt)
+}
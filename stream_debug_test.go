@@ -0,0 +1,37 @@
+//go:build openapidebug
+
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEventStreamChecksEvents(t *testing.T) {
+	api := NewAPI("streams")
+	route := api.Get("/events").HasEventStream(http.StatusOK, EventOf[StreamCreated]("created"))
+	stream := NewEventStream(httptest.NewRecorder(), route, http.StatusOK)
+
+	if err := stream.Send("shipped", StreamShipped{}); err == nil || err.Error() != `event "shipped" is not documented` {
+		t.Errorf("expected an undocumented event error, got %v", err)
+	}
+	if err := stream.Send("created", StreamShipped{}); err == nil || err.Error() != `event "created": expected data of type openapi.StreamCreated, got openapi.StreamShipped` {
+		t.Errorf("expected a type error, got %v", err)
+	}
+	if err := stream.Send("created", StreamCreated{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNDJSONStreamChecksValues(t *testing.T) {
+	api := NewAPI("streams")
+	route := api.Get("/export").HasNDJSONStream(http.StatusOK, ModelOf[StreamCreated]())
+
+	if err := NewNDJSONStream(httptest.NewRecorder(), route, http.StatusOK).Send(StreamShipped{}); err == nil {
+		t.Error("expected a type error")
+	}
+	if err := NewNDJSONStream(httptest.NewRecorder(), route, http.StatusCreated).Send(StreamCreated{}); err == nil {
+		t.Error("expected an undocumented response error")
+	}
+}
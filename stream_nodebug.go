@@ -0,0 +1,6 @@
+//go:build !openapidebug
+
+package openapi
+
+// debugStreams enables the checking of streamed values against their documented models.
+const debugStreams = false
@@ -0,0 +1,121 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEventStream(t *testing.T) {
+	api := NewAPI("streams")
+	route := api.Get("/events").HasEventStream(http.StatusOK, EventOf[StreamCreated]("created"), EventOf[string](""))
+
+	w := httptest.NewRecorder()
+	stream := NewEventStream(w, route, http.StatusOK)
+	if err := stream.Send("created", StreamCreated{ID: "1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := stream.Send("", "hello"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"created\ndata: {}", "created\revent: deleted"} {
+		if err := stream.Send(name, StreamCreated{ID: "2"}); err == nil {
+			t.Errorf("expected an error for the event name %q", name)
+		}
+	}
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != ContentTypeEventStream {
+		t.Errorf("expected content type %q, got %q", ContentTypeEventStream, ct)
+	}
+	expected := "event: created\ndata: {\"id\":\"1\"}\n\ndata: \"hello\"\n\n"
+	if diff := cmp.Diff(expected, w.Body.String()); diff != "" {
+		t.Error(diff)
+	}
+	if !w.Flushed {
+		t.Error("expected the events to be flushed")
+	}
+}
+
+func TestNDJSONStream(t *testing.T) {
+	api := NewAPI("streams")
+	route := api.Get("/export").HasNDJSONStream(http.StatusOK, ModelOf[StreamCreated]())
+
+	w := httptest.NewRecorder()
+	stream := NewNDJSONStream(w, route, http.StatusOK)
+	for _, id := range []string{"1", "2"} {
+		if err := stream.Send(StreamCreated{ID: id}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != ContentTypeNDJSON {
+		t.Errorf("expected content type %q, got %q", ContentTypeNDJSON, ct)
+	}
+	expected := "{\"id\":\"1\"}\n{\"id\":\"2\"}\n"
+	if diff := cmp.Diff(expected, w.Body.String()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestCheckModel(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    Model
+		value    any
+		expected string
+	}{
+		{
+			name:  "same type",
+			model: ModelOf[StreamCreated](),
+			value: StreamCreated{},
+		},
+		{
+			name:  "pointer to the type",
+			model: ModelOf[StreamCreated](),
+			value: &StreamCreated{},
+		},
+		{
+			name:  "value of a pointer model",
+			model: ModelOf[*StreamCreated](),
+			value: StreamCreated{},
+		},
+		{
+			name:  "nil slice",
+			model: ModelOf[[]StreamCreated](),
+			value: nil,
+		},
+		{
+			name:  "no schema",
+			model: NoSchema(),
+			value: 1,
+		},
+		{
+			name:     "different type",
+			model:    ModelOf[StreamCreated](),
+			value:    StreamShipped{},
+			expected: "expected data of type openapi.StreamCreated, got openapi.StreamShipped",
+		},
+		{
+			name:     "nil struct",
+			model:    ModelOf[StreamCreated](),
+			value:    nil,
+			expected: "expected data of type openapi.StreamCreated, got <nil>",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var actual string
+			if err := checkModel(test.model, test.value); err != nil {
+				actual = err.Error()
+			}
+			if actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
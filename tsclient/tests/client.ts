@@ -0,0 +1,205 @@
+// Code generated by github.com/ihezebin/openapi/tsclient. DO NOT EDIT.
+
+/**
+ * Audit records changes to a model.
+ */
+export interface Audit {
+  /**
+   * UpdatedBy is the ID of the user that last changed the model.
+   */
+  updatedBy?: string;
+}
+
+/**
+ * Body wraps the data of every response.
+ */
+export interface Body<T> {
+  /**
+   * Message describes the result.
+   */
+  message: string;
+  data: T;
+  code: number;
+}
+
+/**
+ * Body wraps the data of every response.
+ */
+export interface BodyOfString {
+  /**
+   * Message describes the result.
+   */
+  message: string;
+  data: string;
+  code: number;
+}
+
+/**
+ * Page selects the items of a list.
+ */
+export interface Page {
+  offset: number;
+  size: number;
+}
+
+/**
+ * Status of a user.
+ */
+export type Status = "active" | "inactive";
+
+/**
+ * User of the service.
+ */
+export interface User {
+  /**
+   * ID of the user.
+   */
+  id: string;
+  /**
+   * Name of the user.
+   *
+   * @deprecated use DisplayName.
+   */
+  name?: string;
+  displayName: string;
+  status: Status;
+  tags: string[];
+  created: string;
+  avatar?: string | null;
+  /**
+   * UpdatedBy is the ID of the user that last changed the model.
+   */
+  updatedBy?: string;
+}
+
+export interface ClientOptions {
+  /** Base URL of the API, e.g. http://localhost:8080 */
+  baseUrl: string;
+  /** Headers added to every request, e.g. an Authorization header. */
+  headers?: Record<string, string>;
+  /** Implementation of fetch, defaults to the global fetch. */
+  fetch?: typeof fetch;
+}
+
+/** Thrown when the API responds with a status code that isn't a result of the operation. */
+export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    readonly body: unknown,
+  ) {
+    super(`unexpected status code ${status}`);
+    this.name = "ApiError";
+  }
+}
+
+type Value = string | number | boolean;
+type Values = Record<string, Value | Value[] | undefined>;
+
+/** Returns the properties of an object query param as query values, using the style of the param. */
+function objectParam(name: string, value: object | null | undefined, style: string, explode: boolean): Values {
+  const entries = Object.entries(value ?? {})
+    .filter(([, v]) => v !== undefined && v !== null)
+    .map(([k, v]): [string, string] => [k, typeof v === "object" ? JSON.stringify(v) : String(v)])
+    .sort(([a], [b]) => (a < b ? -1 : a > b ? 1 : 0));
+  if (style === "deepObject") {
+    return Object.fromEntries(entries.map(([k, v]) => [`${name}[${k}]`, v]));
+  }
+  if (explode) {
+    return Object.fromEntries(entries);
+  }
+  return entries.length > 0 ? { [name]: entries.flat().join(",") } : {};
+}
+
+/** Client of the users API. */
+export class Client {
+  constructor(private readonly options: ClientOptions) {}
+
+  /**
+   * `GET /users`
+   */
+  listUsers(params: { limit?: number; "X-Tenant": string }): Promise<Body<User[]>> {
+    return this.request<Body<User[]>>("GET", `/users`, [200], { limit: params.limit }, { "X-Tenant": params["X-Tenant"] }, undefined);
+  }
+
+  /**
+   * Create a user
+   *
+   * `POST /users`
+   */
+  postUsers(body: User): Promise<User> {
+    return this.request<User>("POST", `/users`, [201], undefined, undefined, undefined, body);
+  }
+
+  /**
+   * `GET /users/{ids}/roles`
+   */
+  listRoles(params: { ids: number[]; filter?: Record<string, string>; page?: Page; roles?: string[]; since?: string; sizes?: number[]; "X-Zones"?: string[]; prefs?: string[]; session: string }): Promise<string[]> {
+    return this.request<string[]>("GET", `/users/;ids=${params.ids.map((v) => encodeURIComponent(String(v))).join(",")}/roles`, [200], { ...objectParam("filter", params.filter, "deepObject", true), ...objectParam("page", params.page, "form", false), roles: params.roles, since: params.since, sizes: params.sizes?.join("|") }, { "X-Zones": params["X-Zones"]?.join(",") }, { prefs: params.prefs?.join(","), session: params.session });
+  }
+
+  /**
+   * `DELETE /users/{id}`
+   */
+  deleteUsersById(params: { id: string }): Promise<void> {
+    return this.request<void>("DELETE", `/users/${encodeURIComponent(String(params.id))}`, [], undefined, undefined, undefined);
+  }
+
+  /**
+   * Get a user by ID.
+   *
+   * `GET /users/{id}`
+   *
+   * @deprecated the operation is deprecated.
+   */
+  getUser(params: { id: string }): Promise<Body<User>> {
+    return this.request<Body<User>>("GET", `/users/${encodeURIComponent(String(params.id))}`, [200], undefined, undefined, undefined);
+  }
+
+  private async request<T>(method: string, path: string, success: number[], query?: Values, headers?: Values, cookies?: Values, body?: unknown): Promise<T> {
+    let url = this.options.baseUrl.replace(/\/$/, "") + path;
+    const search = new URLSearchParams();
+    for (const [name, value] of Object.entries(query ?? {})) {
+      if (Array.isArray(value)) {
+        value.forEach((v) => search.append(name, String(v)));
+      } else if (value !== undefined) {
+        search.set(name, String(value));
+      }
+    }
+    if (search.toString()) {
+      url += "?" + search.toString();
+    }
+    const h = new Headers(this.options.headers);
+    for (const [name, value] of Object.entries(headers ?? {})) {
+      if (value !== undefined) {
+        h.set(name, String(value));
+      }
+    }
+    const cookie = Object.entries(cookies ?? {})
+      .filter(([, value]) => value !== undefined)
+      .map(([name, value]) => `${name}=${String(value)}`);
+    if (cookie.length > 0) {
+      // Cookies are separated by semicolons, so they're added to the cookies of the options.
+      const existing = h.get("Cookie");
+      h.set("Cookie", (existing ? [existing, ...cookie] : cookie).join("; "));
+    }
+    h.set("Accept", "application/json");
+    let payload: string | undefined;
+    if (body !== undefined) {
+      h.set("Content-Type", "application/json");
+      payload = JSON.stringify(body);
+    }
+    const response = await (this.options.fetch ?? fetch)(url, { method, headers: h, body: payload });
+    const text = await response.text();
+    let data: unknown = text;
+    try {
+      data = text ? JSON.parse(text) : undefined;
+    } catch {
+      // The body isn't JSON.
+    }
+    const ok = success.length > 0 ? success.includes(response.status) : response.ok;
+    if (!ok) {
+      throw new ApiError(response.status, data);
+    }
+    return data as T;
+  }
+}
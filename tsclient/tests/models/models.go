@@ -0,0 +1,56 @@
+// Package models contains the models used to test the TypeScript generator.
+package models
+
+import (
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Body wraps the data of every response.
+type Body[T any] struct {
+	// Message describes the result.
+	Message string `json:"message"`
+	Data    T      `json:"data"`
+	Code    int    `json:"code"`
+}
+
+// Status of a user.
+type Status string
+
+const (
+	StatusActive   Status = "active"
+	StatusInactive Status = "inactive"
+)
+
+func (Status) ApplyCustomSchema(s *openapi3.Schema) {
+	s.Enum = []any{StatusActive, StatusInactive}
+}
+
+// User of the service.
+type User struct {
+	// ID of the user.
+	ID string `json:"id"`
+	// Name of the user.
+	//
+	// Deprecated: use DisplayName.
+	Name        string    `json:"name,omitempty"`
+	DisplayName string    `json:"displayName"`
+	Status      Status    `json:"status"`
+	Tags        []string  `json:"tags"`
+	Created     time.Time `json:"created"`
+	Avatar      *string   `json:"avatar"`
+	Audit
+}
+
+// Audit records changes to a model.
+type Audit struct {
+	// UpdatedBy is the ID of the user that last changed the model.
+	UpdatedBy string `json:"updatedBy,omitempty"`
+}
+
+// Page selects the items of a list.
+type Page struct {
+	Offset int `json:"offset"`
+	Size   int `json:"size"`
+}
@@ -0,0 +1,691 @@
+// Package tsclient generates TypeScript types and a fetch based client for an openapi.API.
+//
+// Types are generated from the Go types of the registered models rather than from the
+// OpenAPI specification, so Go doc comments are kept as TSDoc, enums become unions of
+// string literals, and instantiations of generic Go types, such as Body[T], share a
+// generic TypeScript interface where possible.
+package tsclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/ihezebin/openapi"
+)
+
+// Generate the TypeScript source of the types and client of the API.
+//
+// The types are the models of the routes, and any models registered with API.RegisterModel.
+// Each route becomes a method of the Client class, named from the OperationID of the route,
+// or from the method and pattern if it isn't set. Struct and map query parameters are sent as
+// their properties, using the style of the parameter. Cookie parameters are sent in the Cookie
+// header, which browsers don't let fetch set, so they're only sent by other runtimes, e.g. Node.js.
+func Generate(api *openapi.API) ([]byte, error) {
+	// Creating the specification registers the models of the routes.
+	if _, err := api.Spec(); err != nil {
+		return nil, fmt.Errorf("create spec err: %w", err)
+	}
+	g := newGenerator(api)
+	registered := api.RegisteredModels()
+	names := make([]string, 0, len(registered))
+	for name, m := range registered {
+		if m.Type == nil {
+			continue
+		}
+		names = append(names, name)
+		if len(m.Schema.Enum) > 0 {
+			g.enums[m.Type] = m.Schema.Enum
+		}
+	}
+	sort.Strings(names)
+	routes := api.SortedRoutes()
+
+	for _, name := range names {
+		g.collect(registered[name].Type)
+	}
+	for _, r := range routes {
+		if r.Models.Request.Type != nil {
+			g.collect(r.Models.Request.Type)
+		}
+		for _, m := range r.Models.Responses {
+			if m.Type != nil {
+				g.collect(m.Type)
+			}
+		}
+	}
+
+	for _, name := range names {
+		if _, err := g.tsType(registered[name].Type, nil); err != nil {
+			return nil, fmt.Errorf("model %s: %w", name, err)
+		}
+	}
+	f := file{
+		Title: api.Name,
+	}
+	methodNames := map[string]string{}
+	for _, r := range routes {
+		route := fmt.Sprintf("%s %s", r.Method, r.Pattern)
+		op, err := g.operation(r)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", route, err)
+		}
+		if existing, ok := methodNames[op.Name]; ok {
+			return nil, fmt.Errorf("%s: method name %q is already used by %s, set a unique operation ID", route, op.Name, existing)
+		}
+		methodNames[op.Name] = route
+		f.Operations = append(f.Operations, op)
+	}
+	f.Declarations = g.decls
+	sort.Slice(f.Declarations, func(i, j int) bool {
+		return f.Declarations[i].Name < f.Declarations[j].Name
+	})
+
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, f); err != nil {
+		return nil, fmt.Errorf("render client err: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+type file struct {
+	Title        string
+	Declarations []*declaration
+	Operations   []*operation
+}
+
+// declaration of a TypeScript interface, or a type alias if Type is set.
+type declaration struct {
+	Name   string
+	Params []string
+	Doc    string
+	Type   string
+	Fields []field
+}
+
+func (d *declaration) TypeParams() string {
+	if len(d.Params) == 0 {
+		return ""
+	}
+	return "<" + strings.Join(d.Params, ", ") + ">"
+}
+
+type field struct {
+	Name     string
+	Type     string
+	Optional bool
+	Doc      string
+}
+
+func (f field) Key() string {
+	return propertyKey(f.Name)
+}
+
+type operation struct {
+	Name       string
+	Method     string
+	Pattern    string
+	Summary    string
+	Doc        string
+	Deprecated bool
+	Params     []param
+	Path       string
+	// RequestType is empty if the route doesn't have a request model.
+	RequestType string
+	ResultType  string
+	Success     []int
+}
+
+// ParamsType is the type of the params argument of the method.
+func (op *operation) ParamsType() string {
+	props := make([]string, len(op.Params))
+	for i, p := range op.Params {
+		optional := ""
+		if p.Optional {
+			optional = "?"
+		}
+		props[i] = fmt.Sprintf("%s%s: %s", propertyKey(p.Name), optional, p.Type)
+	}
+	return "{ " + strings.Join(props, "; ") + " }"
+}
+
+// Values returns an object literal of the params in the location, or undefined if there aren't any.
+func (op *operation) Values(in string) string {
+	var props []string
+	for _, p := range op.Params {
+		switch {
+		case p.In != in:
+		case p.Object:
+			// Object params are spread, since they're sent as their properties.
+			props = append(props, "..."+p.Value())
+		default:
+			props = append(props, propertyKey(p.Name)+": "+p.Value())
+		}
+	}
+	if len(props) == 0 {
+		return "undefined"
+	}
+	return "{ " + strings.Join(props, ", ") + " }"
+}
+
+// SuccessList is an array literal of the status codes that return the result.
+func (op *operation) SuccessList() string {
+	codes := make([]string, len(op.Success))
+	for i, s := range op.Success {
+		codes[i] = fmt.Sprint(s)
+	}
+	return "[" + strings.Join(codes, ", ") + "]"
+}
+
+type param struct {
+	Name     string
+	In       string
+	Type     string
+	Optional bool
+	// Array is true if the param has multiple values, which are joined with Sep, unless it's an
+	// exploded query param, which is sent once for each value. Path params start with Prefix.
+	Array  bool
+	Sep    string
+	Prefix string
+	// Object is true for structs and maps in the query, which are sent as their properties using
+	// Style and Explode, e.g. ?filter[status]=open in the deepObject style.
+	Object  bool
+	Style   string
+	Explode bool
+}
+
+// Access is an expression that reads the param from the params argument.
+func (p param) Access() string {
+	if isIdentifier(p.Name) {
+		return "params." + p.Name
+	}
+	return "params[" + stringLiteral(p.Name) + "]"
+}
+
+// Value is an expression that reads the value of a query or header param, joining the values of
+// arrays using the style of the param.
+func (p param) Value() string {
+	if p.Object {
+		return fmt.Sprintf("objectParam(%s, %s, %s, %t)", stringLiteral(p.Name), p.Access(), stringLiteral(p.Style), p.Explode)
+	}
+	if !p.Array || p.Sep == "" {
+		return p.Access()
+	}
+	access := p.Access()
+	if p.Optional {
+		access += "?"
+	}
+	return access + ".join(" + stringLiteral(p.Sep) + ")"
+}
+
+// PathValue is an expression that formats the value of a path param, escaping its values.
+func (p param) PathValue() string {
+	if !p.Array {
+		return p.Prefix + "${encodeURIComponent(String(" + p.Access() + "))}"
+	}
+	return p.Prefix + "${" + p.Access() + ".map((v) => encodeURIComponent(String(v))).join(" + stringLiteral(p.Sep) + ")}"
+}
+
+type generator struct {
+	api *openapi.API
+	// enums are the values of enum types, from the schemas of the registered models.
+	enums map[reflect.Type][]any
+	// collected types, and the instantiations of generic types by generic key.
+	collected map[reflect.Type]bool
+	instances map[string][]reflect.Type
+	generics  map[string]*generic
+	// names of declared types.
+	names    map[reflect.Type]string
+	used     map[string]bool
+	decls    []*declaration
+	comments map[string]map[string]string
+}
+
+func newGenerator(api *openapi.API) *generator {
+	g := &generator{
+		api:       api,
+		enums:     map[reflect.Type][]any{},
+		collected: map[reflect.Type]bool{},
+		instances: map[string][]reflect.Type{},
+		generics:  map[string]*generic{},
+		names:     map[reflect.Type]string{},
+		used:      map[string]bool{},
+		comments:  map[string]map[string]string{},
+	}
+	for _, name := range reservedNames {
+		g.used[name] = true
+	}
+	return g
+}
+
+func (g *generator) operation(r *openapi.Route) (op *operation, err error) {
+	op = &operation{
+		Name:       methodName(r),
+		Method:     string(r.Method),
+		Pattern:    string(r.Pattern),
+		Summary:    r.Summary,
+		Doc:        r.Description,
+		Deprecated: r.Deprecated,
+	}
+	pathParams, err := r.PathParams()
+	if err != nil {
+		return nil, err
+	}
+	add := func(in, name string, o paramOptions) error {
+		p, err := g.param(in, name, o)
+		if err != nil {
+			return fmt.Errorf("%s parameter %q: %w", in, name, err)
+		}
+		op.Params = append(op.Params, p)
+		return nil
+	}
+	for _, name := range sortedKeys(pathParams) {
+		p := pathParams[name]
+		if err = add("path", name, paramOptions{Type: p.Type, Model: p.Model, Required: true, Style: p.Style, Explode: p.Explode}); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range sortedKeys(r.Params.Query) {
+		p := r.Params.Query[name]
+		if err = add("query", name, paramOptions{Type: p.Type, Model: p.Model, Required: p.Required, Style: p.Style, Explode: p.Explode}); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range sortedKeys(r.Params.Header) {
+		p := r.Params.Header[name]
+		if err = add("header", name, paramOptions{Type: p.Type, Model: p.Model, Required: p.Required, Explode: p.Explode}); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range sortedKeys(r.Params.Cookie) {
+		p := r.Params.Cookie[name]
+		if err = add("cookie", name, paramOptions{Type: p.Type, Model: p.Model, Required: p.Required, Explode: p.Explode}); err != nil {
+			return nil, err
+		}
+	}
+	seen := map[string]string{}
+	for _, p := range op.Params {
+		if in, ok := seen[p.Name]; ok {
+			return nil, fmt.Errorf("%s parameter %q has the same name as a %s parameter", p.In, p.Name, in)
+		}
+		seen[p.Name] = p.In
+	}
+	if op.Path, err = pathTemplate(string(r.Pattern), op.Params); err != nil {
+		return nil, err
+	}
+	if r.Models.Request.Type != nil {
+		if op.RequestType, err = g.tsType(r.Models.Request.Type, nil); err != nil {
+			return nil, fmt.Errorf("request model: %w", err)
+		}
+	}
+	statuses := make([]int, 0, len(r.Models.Responses))
+	for status := range r.Models.Responses {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+	var results []string
+	for _, status := range statuses {
+		m := r.Models.Responses[status]
+		if m.Type == nil {
+			continue
+		}
+		t, err := g.tsType(m.Type, nil)
+		if err != nil {
+			return nil, fmt.Errorf("response model %d: %w", status, err)
+		}
+		if status < 200 || status > 299 {
+			continue
+		}
+		op.Success = append(op.Success, status)
+		if !containsString(results, t) {
+			results = append(results, t)
+		}
+	}
+	op.ResultType = "void"
+	if len(results) > 0 {
+		op.ResultType = strings.Join(results, " | ")
+	}
+	return op, nil
+}
+
+// paramOptions are the options of a path, query, header or cookie parameter.
+type paramOptions struct {
+	Type     openapi.PrimitiveType
+	Model    openapi.Model
+	Required bool
+	Style    string
+	Explode  *bool
+}
+
+// param returns the parameter of the method. Its type is the type of the model of the parameter,
+// if it has one, and arrays are sent using the style of the parameter.
+func (g *generator) param(in, name string, o paramOptions) (p param, err error) {
+	p = param{Name: name, In: in, Type: primitiveType(o.Type), Optional: !o.Required}
+	t := o.Model.Type
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t != nil {
+		if p.Type, err = g.tsType(t, nil); err != nil {
+			return p, err
+		}
+		switch {
+		case implements(t, textMarshalerType):
+		case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+			p.Array = true
+		case (t.Kind() == reflect.Struct || t.Kind() == reflect.Map) && in != "query":
+			return p, fmt.Errorf("object parameters of type %v aren't supported by the client", t)
+		case t.Kind() == reflect.Struct || t.Kind() == reflect.Map:
+			p.Object = true
+		}
+	}
+	style, explode := openapi.ParamStyle(in, o.Style, o.Explode)
+	p.Style, p.Explode = style, explode
+	p.Sep = ","
+	switch style {
+	case "form":
+		if explode && in == "query" {
+			p.Sep = ""
+		}
+	case "spaceDelimited":
+		p.Sep = " "
+	case "pipeDelimited":
+		p.Sep = "|"
+	case "label":
+		p.Prefix = "."
+		if explode {
+			p.Sep = "."
+		}
+	case "matrix":
+		p.Prefix = ";" + name + "="
+		if explode {
+			p.Sep = p.Prefix
+		}
+	}
+	return p, nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) (op []string) {
+	for k := range m {
+		op = append(op, k)
+	}
+	sort.Strings(op)
+	return op
+}
+
+func primitiveType(t openapi.PrimitiveType) string {
+	switch t {
+	case openapi.PrimitiveTypeBool:
+		return "boolean"
+	case openapi.PrimitiveTypeInteger, openapi.PrimitiveTypeFloat64:
+		return "number"
+	}
+	return "string"
+}
+
+var templateLiteralEscaper = strings.NewReplacer("\\", "\\\\", "`", "\\`", "${", "\\${")
+
+// pathTemplate returns a template literal that builds the path of the route from the params.
+func pathTemplate(pattern string, params []param) (string, error) {
+	pathParams := map[string]param{}
+	for _, p := range params {
+		if p.In == "path" {
+			pathParams[p.Name] = p
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString("`")
+	for len(pattern) > 0 {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			sb.WriteString(templateLiteralEscaper.Replace(pattern))
+			break
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated path parameter in %q", pattern)
+		}
+		sb.WriteString(templateLiteralEscaper.Replace(pattern[:start]))
+		// Strip routers' regular expressions, e.g. {id:[0-9]+}.
+		name, _, _ := strings.Cut(pattern[start+1:start+end], ":")
+		p, ok := pathParams[name]
+		if !ok {
+			return "", fmt.Errorf("path parameter %q is not declared", name)
+		}
+		sb.WriteString(p.PathValue())
+		pattern = pattern[start+end+1:]
+	}
+	sb.WriteString("`")
+	return sb.String(), nil
+}
+
+// methodName returns the OperationID of the route in lower camel case, or a name based on
+// the method and pattern, e.g. GET /users/{id} becomes getUsersById.
+func methodName(r *openapi.Route) string {
+	if r.OperationID != "" {
+		return camelCase(r.OperationID)
+	}
+	words := []string{strings.ToLower(string(r.Method))}
+	segments := strings.Split(strings.Trim(string(r.Pattern), "/"), "/")
+	for _, s := range segments {
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			name, _, _ := strings.Cut(s[1:len(s)-1], ":")
+			words = append(words, "by", name)
+			continue
+		}
+		words = append(words, s)
+	}
+	if len(segments) == 1 && segments[0] == "" {
+		words = append(words, "root")
+	}
+	return camelCase(strings.Join(words, "_"))
+}
+
+// camelCase converts a name such as "get-user_id" to "getUserId".
+func camelCase(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var sb strings.Builder
+	for i, p := range parts {
+		runes := []rune(p)
+		if i == 0 {
+			runes[0] = unicode.ToLower(runes[0])
+		} else {
+			runes[0] = unicode.ToUpper(runes[0])
+		}
+		sb.WriteString(string(runes))
+	}
+	name := sb.String()
+	if name == "" || unicode.IsDigit([]rune(name)[0]) {
+		name = "_" + name
+	}
+	return name
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func isIdentifier(s string) bool {
+	return identifier.MatchString(s)
+}
+
+func propertyKey(name string) string {
+	if isIdentifier(name) {
+		return name
+	}
+	return stringLiteral(name)
+}
+
+func stringLiteral(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// tsDoc formats a Go doc comment as a TSDoc comment, with a trailing newline and indent.
+func tsDoc(indent, text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("/**\n")
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t")
+		if rest, ok := strings.CutPrefix(line, "Deprecated:"); ok {
+			line = "@deprecated" + rest
+		}
+		line = strings.ReplaceAll(line, "*/", "*\\/")
+		if line == "" {
+			sb.WriteString(indent + " *\n")
+			continue
+		}
+		sb.WriteString(indent + " * " + line + "\n")
+	}
+	sb.WriteString(indent + " */\n" + indent)
+	return sb.String()
+}
+
+// operationDoc combines the summary, description and path of the operation.
+func operationDoc(op *operation) string {
+	var paragraphs []string
+	if op.Summary != "" {
+		paragraphs = append(paragraphs, op.Summary)
+	}
+	if op.Doc != "" {
+		paragraphs = append(paragraphs, op.Doc)
+	}
+	paragraphs = append(paragraphs, fmt.Sprintf("`%s %s`", op.Method, op.Pattern))
+	if op.Deprecated {
+		paragraphs = append(paragraphs, "Deprecated: the operation is deprecated.")
+	}
+	return tsDoc("  ", strings.Join(paragraphs, "\n\n"))
+}
+
+var fileTemplate = template.Must(template.New("client").Funcs(template.FuncMap{
+	"doc":          tsDoc,
+	"operationDoc": operationDoc,
+	"string":       stringLiteral,
+}).Parse(`// Code generated by github.com/ihezebin/openapi/tsclient. DO NOT EDIT.
+{{ range .Declarations }}
+{{ doc "" .Doc }}
+{{- if .Type -}}
+export type {{ .Name }}{{ .TypeParams }} = {{ .Type }};
+{{ else -}}
+export interface {{ .Name }}{{ .TypeParams }} {
+{{- range .Fields }}
+  {{ doc "  " .Doc }}{{ .Key }}{{ if .Optional }}?{{ end }}: {{ .Type }};
+{{- end }}
+}
+{{ end }}{{ end }}
+export interface ClientOptions {
+  /** Base URL of the API, e.g. http://localhost:8080 */
+  baseUrl: string;
+  /** Headers added to every request, e.g. an Authorization header. */
+  headers?: Record<string, string>;
+  /** Implementation of fetch, defaults to the global fetch. */
+  fetch?: typeof fetch;
+}
+
+/** Thrown when the API responds with a status code that isn't a result of the operation. */
+export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    readonly body: unknown,
+  ) {
+    super(` + "`unexpected status code ${status}`" + `);
+    this.name = "ApiError";
+  }
+}
+
+type Value = string | number | boolean;
+type Values = Record<string, Value | Value[] | undefined>;
+
+/** Returns the properties of an object query param as query values, using the style of the param. */
+function objectParam(name: string, value: object | null | undefined, style: string, explode: boolean): Values {
+  const entries = Object.entries(value ?? {})
+    .filter(([, v]) => v !== undefined && v !== null)
+    .map(([k, v]): [string, string] => [k, typeof v === "object" ? JSON.stringify(v) : String(v)])
+    .sort(([a], [b]) => (a < b ? -1 : a > b ? 1 : 0));
+  if (style === "deepObject") {
+    return Object.fromEntries(entries.map(([k, v]) => [` + "`${name}[${k}]`" + `, v]));
+  }
+  if (explode) {
+    return Object.fromEntries(entries);
+  }
+  return entries.length > 0 ? { [name]: entries.flat().join(",") } : {};
+}
+
+/** Client of the {{ .Title }} API. */
+export class Client {
+  constructor(private readonly options: ClientOptions) {}
+{{ range .Operations }}
+  {{ operationDoc . }}{{ .Name }}(
+{{- if .Params }}params: {{ .ParamsType }}{{ if .RequestType }}, {{ end }}{{ end }}
+{{- if .RequestType }}body: {{ .RequestType }}{{ end }}): Promise<{{ .ResultType }}> {
+    return this.request<{{ .ResultType }}>({{ string .Method }}, {{ .Path }}, {{ .SuccessList }}, {{ .Values "query" }}, {{ .Values "header" }}, {{ .Values "cookie" }}{{ if .RequestType }}, body{{ end }});
+  }
+{{ end }}
+  private async request<T>(method: string, path: string, success: number[], query?: Values, headers?: Values, cookies?: Values, body?: unknown): Promise<T> {
+    let url = this.options.baseUrl.replace(/\/$/, "") + path;
+    const search = new URLSearchParams();
+    for (const [name, value] of Object.entries(query ?? {})) {
+      if (Array.isArray(value)) {
+        value.forEach((v) => search.append(name, String(v)));
+      } else if (value !== undefined) {
+        search.set(name, String(value));
+      }
+    }
+    if (search.toString()) {
+      url += "?" + search.toString();
+    }
+    const h = new Headers(this.options.headers);
+    for (const [name, value] of Object.entries(headers ?? {})) {
+      if (value !== undefined) {
+        h.set(name, String(value));
+      }
+    }
+    const cookie = Object.entries(cookies ?? {})
+      .filter(([, value]) => value !== undefined)
+      .map(([name, value]) => ` + "`${name}=${String(value)}`" + `);
+    if (cookie.length > 0) {
+      // Cookies are separated by semicolons, so they're added to the cookies of the options.
+      const existing = h.get("Cookie");
+      h.set("Cookie", (existing ? [existing, ...cookie] : cookie).join("; "));
+    }
+    h.set("Accept", "application/json");
+    let payload: string | undefined;
+    if (body !== undefined) {
+      h.set("Content-Type", "application/json");
+      payload = JSON.stringify(body);
+    }
+    const response = await (this.options.fetch ?? fetch)(url, { method, headers: h, body: payload });
+    const text = await response.text();
+    let data: unknown = text;
+    try {
+      data = text ? JSON.parse(text) : undefined;
+    } catch {
+      // The body isn't JSON.
+    }
+    const ok = success.length > 0 ? success.includes(response.status) : response.ok;
+    if (!ok) {
+      throw new ApiError(response.status, data);
+    }
+    return data as T;
+  }
+}
+`))
@@ -0,0 +1,112 @@
+package tsclient
+
+import (
+	"flag"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ihezebin/openapi"
+	"github.com/ihezebin/openapi/tsclient/tests/models"
+)
+
+var update = flag.Bool("update", false, "update the expected output in tests/client.ts")
+
+const expectedClientFile = "tests/client.ts"
+
+func newTestAPI(t *testing.T) *openapi.API {
+	api := openapi.NewAPI("users")
+	api.StripPkgPaths = []string{"github.com/ihezebin/openapi"}
+	if _, _, err := api.RegisterModel(openapi.ModelOf[models.Audit]()); err != nil {
+		t.Fatalf("failed to register model: %v", err)
+	}
+	api.Get("/users").
+		HasQueryParameter("limit", openapi.QueryParam{
+			Type: openapi.PrimitiveTypeInteger,
+		}).
+		HasHeaderParameter("X-Tenant", openapi.HeaderParam{
+			Required: true,
+		}).
+		HasResponseModel(http.StatusOK, openapi.ModelOf[models.Body[[]models.User]]()).
+		HasOperationID("listUsers")
+	api.Post("/users").
+		HasRequestModel(openapi.ModelOf[models.User]()).
+		HasResponseModel(http.StatusCreated, openapi.ModelOf[models.User]()).
+		HasSummary("Create a user")
+	api.Get("/users/{id}").
+		HasPathParameter("id", openapi.PathParam{}).
+		HasResponseModel(http.StatusOK, openapi.ModelOf[models.Body[models.User]]()).
+		HasResponseModel(http.StatusNotFound, openapi.ModelOf[models.Body[string]]()).
+		HasOperationID("getUser").
+		HasDescription("Get a user by ID.").
+		HasDeprecated(true)
+	api.Delete("/users/{id}").
+		HasPathParameter("id", openapi.PathParam{}).
+		HasResponseModel(http.StatusNotFound, openapi.ModelOf[models.Body[string]]())
+	api.Get("/users/{ids}/roles").
+		HasPathParameter("ids", openapi.PathParam{Model: openapi.ModelOf[[]int](), Style: "matrix"}).
+		HasParams(openapi.ParamsOf[struct {
+			Roles   []string          `query:"roles,omitempty"`
+			Sizes   []int             `query:"sizes,style=pipeDelimited,explode=false,omitempty"`
+			Since   time.Time         `query:"since,omitempty"`
+			Filter  map[string]string `query:"filter,style=deepObject,omitempty"`
+			Page    *models.Page      `query:"page,explode=false,omitempty"`
+			Zones   []string          `header:"X-Zones,omitempty"`
+			Session string            `cookie:"session"`
+			Prefs   []string          `cookie:"prefs,omitempty"`
+		}]()).
+		HasResponseModel(http.StatusOK, openapi.ModelOf[[]string]()).
+		HasOperationID("listRoles")
+	return api
+}
+
+func TestGenerate(t *testing.T) {
+	ts, err := Generate(newTestAPI(t))
+	if err != nil {
+		t.Fatalf("failed to generate client: %v", err)
+	}
+	if *update {
+		if err = os.WriteFile(expectedClientFile, ts, 0o644); err != nil {
+			t.Fatalf("failed to write client: %v", err)
+		}
+	}
+	expected, err := os.ReadFile(expectedClientFile)
+	if err != nil {
+		t.Fatalf("failed to read expected client: %v", err)
+	}
+	if diff := cmp.Diff(string(expected), string(ts)); diff != "" {
+		t.Errorf("generated client doesn't match %s, run go test -update to regenerate:\n%s", expectedClientFile, diff)
+	}
+}
+
+func TestSplitTypeArgs(t *testing.T) {
+	type pair[A, B any] struct{}
+	tests := []struct {
+		typ      reflect.Type
+		expected []string
+	}{
+		{
+			typ:      reflect.TypeOf(models.Body[map[string][]int]{}),
+			expected: []string{"map[string][]int"},
+		},
+		{
+			typ:      reflect.TypeOf(pair[models.Body[int], string]{}),
+			expected: []string{"github.com/ihezebin/openapi/tsclient/tests/models.Body[int]", "string"},
+		},
+		{
+			typ: reflect.TypeOf(pair[struct {
+				A string `json:"a,omitempty"`
+			}, any]{}),
+			expected: []string{`struct { A string "json:\"a,omitempty\"" }`, "interface {}"},
+		},
+	}
+	for _, test := range tests {
+		actual := splitTypeArgs(test.typ.Name())
+		if diff := cmp.Diff(test.expected, actual); diff != "" {
+			t.Errorf("%s: %s", test.typ.Name(), diff)
+		}
+	}
+}
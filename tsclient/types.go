@@ -0,0 +1,555 @@
+package tsclient
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/ihezebin/openapi/getcomments/parser"
+)
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+// implements returns true if t, or a pointer to t, implements the interface.
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || t.Kind() != reflect.Pointer && reflect.PointerTo(t).Implements(iface)
+}
+
+// reservedNames are declared by the generated client, so can't be used for models.
+var reservedNames = []string{"ApiError", "Client", "ClientOptions", "Value", "Values"}
+
+// collect the types that are reachable from t, recording the instantiations of generic types.
+func (g *generator) collect(t reflect.Type) {
+	if g.collected[t] {
+		return
+	}
+	g.collected[t] = true
+	if _, ok := g.api.KnownType(t); ok {
+		return
+	}
+	if t.Kind() == reflect.Struct && isGeneric(t) {
+		key := genericKey(t)
+		g.instances[key] = append(g.instances[key], t)
+	}
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		g.collect(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if (f.IsExported() || f.Anonymous) && f.Tag.Get("json") != "-" {
+				g.collect(f.Type)
+			}
+		}
+	}
+}
+
+// tsType returns the TypeScript type of t, declaring the named types it uses.
+// Types that are type arguments of params are replaced by the type parameter.
+func (g *generator) tsType(t reflect.Type, params *typeParams) (string, error) {
+	if name, ok := params.lookup(t); ok {
+		return name, nil
+	}
+	if s, ok := g.api.KnownType(t); ok {
+		return schemaType(&s), nil
+	}
+	if t.Kind() == reflect.Pointer {
+		elem, err := g.tsType(t.Elem(), params)
+		if err != nil || strings.HasSuffix(elem, " | null") {
+			return elem, err
+		}
+		return elem + " | null", nil
+	}
+	// Types that marshal themselves as text are encoded as JSON strings.
+	if _, ok := g.enums[t]; !ok && implements(t, textMarshalerType) {
+		return "string", nil
+	}
+	if _, ok := g.enums[t]; ok || (t.Name() != "" && t.PkgPath() != "") {
+		return g.named(t)
+	}
+	return g.literal(t, params)
+}
+
+// literal returns the TypeScript type of t, without declaring it.
+func (g *generator) literal(t reflect.Type, params *typeParams) (string, error) {
+	switch t.Kind() {
+	case reflect.String:
+		return "string", nil
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		if g.api.Int64AsString {
+			return "string", nil
+		}
+		return "number", nil
+	case reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Float32, reflect.Float64:
+		return "number", nil
+	case reflect.Bool:
+		return "boolean", nil
+	case reflect.Interface:
+		return "unknown", nil
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			// []byte is encoded as a base64 string.
+			return "string", nil
+		}
+		elem, err := g.tsType(t.Elem(), params)
+		if strings.Contains(elem, " | ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]", err
+	case reflect.Map:
+		elem, err := g.tsType(t.Elem(), params)
+		return "Record<string, " + elem + ">", err
+	case reflect.Struct:
+		fields, err := g.fields(t, params)
+		if err != nil {
+			return "", err
+		}
+		if len(fields) == 0 {
+			return "Record<string, never>", nil
+		}
+		props := make([]string, len(fields))
+		for i, f := range fields {
+			optional := ""
+			if f.Optional {
+				optional = "?"
+			}
+			props[i] = f.Key() + optional + ": " + f.Type
+		}
+		return "{ " + strings.Join(props, "; ") + " }", nil
+	}
+	return "", fmt.Errorf("type %s is not supported", t)
+}
+
+// fields returns the properties of a struct, as they're encoded by encoding/json.
+func (g *generator) fields(t reflect.Type, params *typeParams) (op []field, err error) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		jsonTags := strings.Split(f.Tag.Get("json"), ",")
+		name := jsonTags[0]
+		if name == "-" && len(jsonTags) == 1 {
+			continue
+		}
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			et := ft
+			if et.Kind() == reflect.Pointer {
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct {
+				// The fields of embedded structs are promoted.
+				embedded, err := g.fields(et, params)
+				if err != nil {
+					return nil, err
+				}
+				op = append(op, embedded...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		typ, err := g.tsType(ft, params)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		if containsString(jsonTags[1:], "string") {
+			typ = "string"
+		}
+		doc, err := g.comment(t, f.Name)
+		if err != nil {
+			return nil, err
+		}
+		op = append(op, field{
+			Name:     name,
+			Type:     typ,
+			Optional: ft.Kind() == reflect.Pointer || containsString(jsonTags[1:], "omitempty"),
+			Doc:      doc,
+		})
+	}
+	return op, nil
+}
+
+// named returns the name of the declaration of t, declaring it if required.
+func (g *generator) named(t reflect.Type) (string, error) {
+	if name, ok := g.names[t]; ok {
+		return name, nil
+	}
+	if t.Kind() == reflect.Struct && isGeneric(t) {
+		return g.genericReference(t)
+	}
+	name := g.uniqueName(declarationName(t), t.PkgPath())
+	g.names[t] = name
+	return name, g.declare(t, name, nil)
+}
+
+func (g *generator) declare(t reflect.Type, name string, params *typeParams) (err error) {
+	d := &declaration{Name: name, Params: params.list()}
+	g.decls = append(g.decls, d)
+	if d.Doc, err = g.comment(t, ""); err != nil {
+		return err
+	}
+	if values, ok := g.enums[t]; ok {
+		literals := make([]string, len(values))
+		for i, v := range values {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("invalid enum value %v of %s: %w", v, t, err)
+			}
+			literals[i] = string(data)
+		}
+		d.Type = strings.Join(literals, " | ")
+		return nil
+	}
+	if t.Kind() == reflect.Struct {
+		d.Fields, err = g.fields(t, params)
+		return err
+	}
+	d.Type, err = g.literal(t, params)
+	return err
+}
+
+// generic is a TypeScript interface that is shared by the instantiations of a generic Go type.
+type generic struct {
+	name   string
+	params []string
+	// signature of the fields of the instantiations that can use the interface.
+	signature string
+}
+
+// genericReference returns a reference to the generic interface of the instantiation t, e.g. Body<Topic>.
+// If t can't be expressed with the interface, e.g. because a type argument is also the type of
+// another field, a separate interface is declared for t.
+func (g *generator) genericReference(t reflect.Type) (string, error) {
+	key := genericKey(t)
+	gen, ok := g.generics[key]
+	if !ok {
+		var err error
+		if gen, err = g.declareGeneric(key, t); err != nil {
+			return "", err
+		}
+	}
+	params := newTypeParams(t)
+	if signature, _ := instanceSignature(t, params); signature != gen.signature {
+		name := g.uniqueName(declarationName(t), t.PkgPath())
+		g.names[t] = name
+		return name, g.declare(t, name, nil)
+	}
+	args := make([]string, len(params.names))
+	for i, p := range params.names {
+		args[i] = "unknown"
+		if at, ok := params.args[p]; ok {
+			var err error
+			if args[i], err = g.tsType(at, nil); err != nil {
+				return "", err
+			}
+		}
+	}
+	ref := gen.name + "<" + strings.Join(args, ", ") + ">"
+	g.names[t] = ref
+	return ref, nil
+}
+
+// declareGeneric declares the generic interface of a generic Go type. The interface is based on the
+// instantiation with the fewest fields replaced by type parameters, since a type argument that is
+// also the type of another field, e.g. string in Body[string], is replaced in both fields.
+func (g *generator) declareGeneric(key string, t reflect.Type) (*generic, error) {
+	instances := g.instances[key]
+	if len(instances) == 0 {
+		instances = []reflect.Type{t}
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].Name() < instances[j].Name()
+	})
+	var best reflect.Type
+	var bestSignature string
+	bestCount := -1
+	for _, instance := range instances {
+		signature, count := instanceSignature(instance, newTypeParams(instance))
+		if bestCount < 0 || count < bestCount {
+			best, bestSignature, bestCount = instance, signature, count
+		}
+	}
+	params := newTypeParams(best)
+	gen := &generic{
+		name:      g.uniqueName(genericBase(best), best.PkgPath()),
+		params:    params.list(),
+		signature: bestSignature,
+	}
+	g.generics[key] = gen
+	return gen, g.declare(best, gen.name, params)
+}
+
+// typeParams maps the type arguments of a generic instantiation to type parameters.
+type typeParams struct {
+	names []string
+	// byType maps the type arguments, as they're written in the name of the type, to parameters.
+	byType map[string]string
+	// args are the types that have been replaced by each parameter.
+	args map[string]reflect.Type
+}
+
+func newTypeParams(t reflect.Type) *typeParams {
+	typeArgs := splitTypeArgs(t.Name())
+	p := &typeParams{
+		byType: map[string]string{},
+		args:   map[string]reflect.Type{},
+	}
+	for i, arg := range typeArgs {
+		name := "T"
+		if len(typeArgs) > 1 {
+			name = "T" + strconv.Itoa(i+1)
+		}
+		p.names = append(p.names, name)
+		// If type arguments are repeated, the first parameter is used.
+		if _, ok := p.byType[arg]; !ok {
+			p.byType[arg] = name
+		}
+	}
+	return p
+}
+
+func (p *typeParams) lookup(t reflect.Type) (name string, ok bool) {
+	if p == nil {
+		return "", false
+	}
+	if name, ok = p.byType[goTypeString(t)]; ok {
+		p.args[name] = t
+	}
+	return name, ok
+}
+
+func (p *typeParams) list() []string {
+	if p == nil {
+		return nil
+	}
+	return p.names
+}
+
+// instanceSignature describes the fields of the generic instantiation t, with its type arguments
+// replaced by type parameters, and returns the number of replacements.
+func instanceSignature(t reflect.Type, params *typeParams) (signature string, count int) {
+	var sb strings.Builder
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		if name, ok := params.lookup(t); ok {
+			sb.WriteString(name)
+			count++
+			return
+		}
+		if t.Name() != "" {
+			sb.WriteString(goTypeString(t))
+			return
+		}
+		switch t.Kind() {
+		case reflect.Pointer:
+			sb.WriteString("*")
+			walk(t.Elem())
+		case reflect.Slice:
+			sb.WriteString("[]")
+			walk(t.Elem())
+		case reflect.Array:
+			sb.WriteString(fmt.Sprintf("[%d]", t.Len()))
+			walk(t.Elem())
+		case reflect.Map:
+			sb.WriteString("map[")
+			walk(t.Key())
+			sb.WriteString("]")
+			walk(t.Elem())
+		case reflect.Struct:
+			sb.WriteString("struct { ")
+			walkFields(t, &sb, walk)
+			sb.WriteString("}")
+		default:
+			sb.WriteString(goTypeString(t))
+		}
+	}
+	walkFields(t, &sb, walk)
+	return sb.String(), count
+}
+
+func walkFields(t reflect.Type, sb *strings.Builder, walk func(t reflect.Type)) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		sb.WriteString(f.Name + " ")
+		walk(f.Type)
+		sb.WriteString(" " + strconv.Quote(string(f.Tag)) + "; ")
+	}
+}
+
+// goTypeString returns the type as it's written in the names of generic instantiations,
+// e.g. *github.com/ihezebin/openapi/examples/models.Topic.
+func goTypeString(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name()
+		}
+		return t.PkgPath() + "." + t.Name()
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + goTypeString(t.Elem())
+	case reflect.Slice:
+		return "[]" + goTypeString(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), goTypeString(t.Elem()))
+	case reflect.Map:
+		return "map[" + goTypeString(t.Key()) + "]" + goTypeString(t.Elem())
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "interface {}"
+		}
+	case reflect.Struct:
+		if t.NumField() == 0 {
+			return "struct {}"
+		}
+		fields := make([]string, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			s := goTypeString(f.Type)
+			if !f.Anonymous {
+				s = f.Name + " " + s
+			}
+			if f.Tag != "" {
+				s += " " + strconv.Quote(string(f.Tag))
+			}
+			fields[i] = s
+		}
+		return "struct { " + strings.Join(fields, "; ") + " }"
+	}
+	return t.String()
+}
+
+func isGeneric(t reflect.Type) bool {
+	return strings.Contains(t.Name(), "[")
+}
+
+func genericBase(t reflect.Type) string {
+	name, _, _ := strings.Cut(t.Name(), "[")
+	return name
+}
+
+func genericKey(t reflect.Type) string {
+	return t.PkgPath() + "." + genericBase(t)
+}
+
+// splitTypeArgs returns the type arguments in the name of a generic instantiation.
+func splitTypeArgs(name string) (op []string) {
+	start := strings.IndexByte(name, '[')
+	if start < 0 || !strings.HasSuffix(name, "]") {
+		return nil
+	}
+	args := name[start+1 : len(name)-1]
+	var depth, last int
+	var quoted bool
+	for i := 0; i < len(args); i++ {
+		switch c := args[i]; {
+		case quoted && c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '[' || c == '{' || c == '(':
+			depth++
+		case c == ']' || c == '}' || c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			op = append(op, args[last:i])
+			last = i + 1
+		}
+	}
+	return append(op, args[last:])
+}
+
+// qualifiedIdent matches package qualified names, e.g. github.com/ihezebin/openapi/examples/models.Topic.
+var qualifiedIdent = regexp.MustCompile(`[\w./~-]*\w\.(\w+)`)
+
+// declarationName is the name of t, with the type arguments of generic types appended,
+// e.g. Body[string] becomes BodyOfString.
+func declarationName(t reflect.Type) string {
+	if !isGeneric(t) {
+		return t.Name()
+	}
+	var sb strings.Builder
+	sb.WriteString(genericBase(t) + "Of")
+	for _, arg := range splitTypeArgs(t.Name()) {
+		if strings.HasPrefix(arg, "struct") {
+			sb.WriteString("Struct")
+			continue
+		}
+		arg = qualifiedIdent.ReplaceAllString(arg, "$1")
+		for _, word := range strings.FieldsFunc(arg, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_')
+		}) {
+			sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return sb.String()
+}
+
+// uniqueName returns name, or if it's already declared, name prefixed with the package name.
+func (g *generator) uniqueName(name, pkgPath string) string {
+	candidate := name
+	if g.used[candidate] && pkgPath != "" {
+		pkg := pkgPath[strings.LastIndexByte(pkgPath, '/')+1:]
+		candidate = camelCase(pkg)
+		candidate = strings.ToUpper(candidate[:1]) + candidate[1:] + name
+	}
+	for i := 2; g.used[candidate]; i++ {
+		candidate = name + strconv.Itoa(i)
+	}
+	g.used[candidate] = true
+	return candidate
+}
+
+// comment returns the doc comment of the type, or of the field of the type if it's set.
+func (g *generator) comment(t reflect.Type, fieldName string) (string, error) {
+	pkg := t.PkgPath()
+	if pkg == "" || t.Name() == "" || pkg == "main" || strings.HasSuffix(pkg, "_test") {
+		return "", nil
+	}
+	pkgComments, ok := g.comments[pkg]
+	if !ok {
+		var err error
+		if pkgComments, err = parser.Get(pkg); err != nil {
+			return "", fmt.Errorf("failed to get comments for package %q: %w", pkg, err)
+		}
+		g.comments[pkg] = pkgComments
+	}
+	key := pkg + "." + genericBase(t)
+	if fieldName != "" {
+		key += "." + fieldName
+	}
+	return pkgComments[key], nil
+}
+
+// schemaType returns the TypeScript type of a known type's schema.
+func schemaType(s *openapi3.Schema) string {
+	t := "unknown"
+	switch {
+	case s.Type.Is(openapi3.TypeString):
+		t = "string"
+	case s.Type.Is(openapi3.TypeInteger), s.Type.Is(openapi3.TypeNumber):
+		t = "number"
+	case s.Type.Is(openapi3.TypeBoolean):
+		t = "boolean"
+	case s.Type.Is(openapi3.TypeArray):
+		t = "unknown[]"
+	case s.Type.Is(openapi3.TypeObject):
+		t = "Record<string, unknown>"
+	}
+	if s.Nullable {
+		t += " | null"
+	}
+	return t
+}
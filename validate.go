@@ -0,0 +1,258 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+// ValidationError is a single problem found when validating a request or response
+// against the OpenAPI specification.
+type ValidationError struct {
+	// In is the location of the problem: path, query, header, cookie or body.
+	In string `json:"in"`
+	// Name of the parameter or header, empty for the body.
+	Name string `json:"name,omitempty"`
+	// Pointer is a JSON pointer to the invalid value within the body, e.g. /tags/0/name.
+	Pointer string `json:"pointer,omitempty"`
+	// Detail of the problem.
+	Detail string `json:"detail"`
+}
+
+func (e ValidationError) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.In)
+	if e.Name != "" {
+		sb.WriteString(" " + e.Name)
+	}
+	if e.Pointer != "" {
+		sb.WriteString(" " + e.Pointer)
+	}
+	sb.WriteString(": " + e.Detail)
+	return sb.String()
+}
+
+// RequestValidatorOpts configures a RequestValidator.
+type RequestValidatorOpts func(*RequestValidator)
+
+// WithRequestErrorHandler sets the function used to render requests that fail validation.
+// The default writes the problem details as application/problem+json.
+func WithRequestErrorHandler(f func(w http.ResponseWriter, r *http.Request, p *ProblemDetails)) RequestValidatorOpts {
+	return func(v *RequestValidator) {
+		v.errorHandler = f
+	}
+}
+
+// WithAuthenticationFunc sets the function used to check the security requirements of routes.
+// The default accepts all requests.
+func WithAuthenticationFunc(f openapi3filter.AuthenticationFunc) RequestValidatorOpts {
+	return func(v *RequestValidator) {
+		v.options.AuthenticationFunc = f
+	}
+}
+
+// RequestValidator validates incoming HTTP requests against the routes, parameters
+// and request models of an API.
+type RequestValidator struct {
+	router       routers.Router
+	options      *openapi3filter.Options
+	errorHandler func(w http.ResponseWriter, r *http.Request, p *ProblemDetails)
+}
+
+// NewRequestValidator creates a RequestValidator from the OpenAPI specification of the API.
+// Routes should be fully configured before the validator is created.
+func NewRequestValidator(api *API, opts ...RequestValidatorOpts) (v *RequestValidator, err error) {
+	spec, err := api.Spec()
+	if err != nil {
+		return nil, fmt.Errorf("create spec err: %w", err)
+	}
+	return newRequestValidator(spec, opts...)
+}
+
+func newRequestValidator(spec *openapi3.T, opts ...RequestValidatorOpts) (v *RequestValidator, err error) {
+	router, err := newRouter(spec)
+	if err != nil {
+		return nil, err
+	}
+	v = &RequestValidator{
+		router: router,
+		options: &openapi3filter.Options{
+			MultiError:         true,
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+		errorHandler: func(w http.ResponseWriter, r *http.Request, p *ProblemDetails) {
+			WriteProblem(w, p)
+		},
+	}
+	for _, o := range opts {
+		o(v)
+	}
+	return v, nil
+}
+
+// newRouter creates a router that matches requests to the operations of the spec.
+// Servers are ignored, so that requests are matched on their path alone.
+//
+// The router uses a copy of the spec that only contains JSON values, since Go values,
+// such as typed enum constants, are not equal to the values decoded from requests.
+func newRouter(spec *openapi3.T) (routers.Router, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("marshal spec err: %w", err)
+	}
+	routerSpec, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("load spec err: %w", err)
+	}
+	routerSpec.Servers = nil
+	router, err := legacy.NewRouter(routerSpec)
+	if err != nil {
+		return nil, fmt.Errorf("create router err: %w", err)
+	}
+	return router, nil
+}
+
+// Validate the request, returning nil if the request is valid.
+// The request body is replaced, so that it can be read again by handlers.
+func (v *RequestValidator) Validate(r *http.Request) *ProblemDetails {
+	route, pathParams, err := findRoute(v.router, r)
+	if err != nil {
+		return routeProblem(err)
+	}
+	input := &openapi3filter.RequestValidationInput{
+		Request:    r,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    v.options,
+	}
+	if err = openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+		p := NewProblemDetails(http.StatusBadRequest, "The request is not valid.")
+		p.Extensions = map[string]any{
+			"errors": validationErrors(err, "", ""),
+		}
+		var secErr *openapi3filter.SecurityRequirementsError
+		if errors.As(err, &secErr) {
+			p = NewProblemDetails(http.StatusUnauthorized, secErr.Error())
+		}
+		return p
+	}
+	return nil
+}
+
+// Middleware validates requests before passing them to the next handler.
+// Invalid requests are rendered by the error handler, and are not passed on.
+func (v *RequestValidator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := v.Validate(r); p != nil {
+			if p.Instance == "" {
+				p.Instance = r.URL.Path
+			}
+			v.errorHandler(w, r, p)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// methods are the HTTP methods that can be documented.
+var methods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+// findRoute matches the request to an operation. If the path matches, but the method
+// doesn't, routers.ErrMethodNotAllowed is returned.
+func findRoute(router routers.Router, r *http.Request) (route *routers.Route, pathParams map[string]string, err error) {
+	route, pathParams, err = router.FindRoute(r)
+	if err == nil || !isRouteError(err, routers.ErrPathNotFound) {
+		return route, pathParams, err
+	}
+	for _, method := range methods {
+		if method == r.Method {
+			continue
+		}
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if _, _, mErr := router.FindRoute(probe); mErr == nil {
+			return nil, nil, routers.ErrMethodNotAllowed
+		}
+	}
+	return nil, nil, err
+}
+
+// isRouteError returns true if err has the same reason as the target, since routers
+// return new errors rather than the sentinel values.
+func isRouteError(err, target error) bool {
+	var re *routers.RouteError
+	return errors.As(err, &re) && re.Reason == target.Error()
+}
+
+func routeProblem(err error) *ProblemDetails {
+	if isRouteError(err, routers.ErrMethodNotAllowed) {
+		return NewProblemDetails(http.StatusMethodNotAllowed, err.Error())
+	}
+	return NewProblemDetails(http.StatusNotFound, err.Error())
+}
+
+// validationErrors flattens the errors returned by openapi3filter into a list.
+func validationErrors(err error, in, name string) (op []ValidationError) {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		for _, err := range e {
+			op = append(op, validationErrors(err, in, name)...)
+		}
+		return op
+	case *openapi3filter.RequestError:
+		in, name = "body", ""
+		if e.Parameter != nil {
+			in, name = e.Parameter.In, e.Parameter.Name
+		}
+		if e.Err == nil {
+			return []ValidationError{{In: in, Name: name, Detail: e.Reason}}
+		}
+		return validationErrors(e.Err, in, name)
+	case *openapi3filter.ResponseError:
+		if e.Err == nil {
+			return []ValidationError{{In: in, Name: name, Detail: e.Reason}}
+		}
+		return validationErrors(e.Err, in, name)
+	case *openapi3.SchemaError:
+		return []ValidationError{{
+			In:      in,
+			Name:    name,
+			Pointer: jsonPointer(e.JSONPointer()),
+			Detail:  e.Reason,
+		}}
+	}
+	if inner := errors.Unwrap(err); inner != nil {
+		return validationErrors(inner, in, name)
+	}
+	return []ValidationError{{In: in, Name: name, Detail: err.Error()}}
+}
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func jsonPointer(path []string) string {
+	if len(path) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for _, p := range path {
+		sb.WriteString("/" + jsonPointerEscaper.Replace(p))
+	}
+	return sb.String()
+}
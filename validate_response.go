@@ -0,0 +1,272 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// ResponseViolation describes how a response differs from the responses declared for its route.
+type ResponseViolation struct {
+	// Method of the request.
+	Method string
+	// Pattern of the route that matched the request.
+	Pattern string
+	// Status code of the response.
+	Status int
+	// Undocumented is set when the route doesn't declare a response for the status code.
+	Undocumented bool
+	// Errors found in the response headers and body.
+	Errors []ValidationError
+}
+
+func (v *ResponseViolation) String() string {
+	prefix := fmt.Sprintf("%s %s %d", v.Method, v.Pattern, v.Status)
+	if v.Undocumented {
+		return prefix + ": undocumented status code"
+	}
+	msgs := make([]string, len(v.Errors))
+	for i, e := range v.Errors {
+		msgs[i] = prefix + " " + e.String()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// ResponseValidatorOpts configures a ResponseValidator.
+type ResponseValidatorOpts func(*ResponseValidator)
+
+// WithResponseViolationHandler sets the function that is called for each response that doesn't
+// match the API. The default logs the violation, which is suitable for staging environments.
+func WithResponseViolationHandler(f func(r *http.Request, v *ResponseViolation)) ResponseValidatorOpts {
+	return func(rv *ResponseValidator) {
+		rv.onViolation = f
+	}
+}
+
+// ResponseValidator checks responses against the status codes, headers and models
+// declared for each route with HasResponseModel and HasResponseHeader.
+type ResponseValidator struct {
+	router      routers.Router
+	onViolation func(r *http.Request, v *ResponseViolation)
+}
+
+// NewResponseValidator creates a ResponseValidator from the OpenAPI specification of the API.
+func NewResponseValidator(api *API, opts ...ResponseValidatorOpts) (v *ResponseValidator, err error) {
+	spec, err := api.Spec()
+	if err != nil {
+		return nil, fmt.Errorf("create spec err: %w", err)
+	}
+	router, err := newRouter(spec)
+	if err != nil {
+		return nil, err
+	}
+	v = &ResponseValidator{
+		router: router,
+		onViolation: func(r *http.Request, v *ResponseViolation) {
+			log.Printf("openapi: response violation: %s", v)
+		},
+	}
+	for _, o := range opts {
+		o(v)
+	}
+	return v, nil
+}
+
+// Validate the response to the request, returning nil if the response matches the API.
+// Responses to requests that don't match a route aren't validated.
+func (v *ResponseValidator) Validate(r *http.Request, status int, header http.Header, body []byte) *ResponseViolation {
+	route, _, err := findRoute(v.router, r)
+	if err != nil {
+		return nil
+	}
+	violation := &ResponseViolation{
+		Method:  r.Method,
+		Pattern: route.Path,
+		Status:  status,
+	}
+	responseRef := route.Operation.Responses.Status(status)
+	if responseRef == nil {
+		responseRef = route.Operation.Responses.Default()
+		if !isDocumentedResponse(responseRef) {
+			violation.Undocumented = true
+			return violation
+		}
+	}
+	response := responseRef.Value
+	violation.Errors = append(violation.Errors, validateResponseHeaders(response.Headers, header)...)
+	if r.Method != http.MethodHead {
+		violation.Errors = append(violation.Errors, validateResponseBody(response.Content, header, body)...)
+	}
+	if len(violation.Errors) == 0 {
+		return nil
+	}
+	return violation
+}
+
+// isDocumentedResponse returns false for the empty default response that
+// is added to every operation.
+func isDocumentedResponse(ref *openapi3.ResponseRef) bool {
+	if ref == nil || ref.Value == nil {
+		return false
+	}
+	r := ref.Value
+	return (r.Description != nil && *r.Description != "") || len(r.Content) > 0 || len(r.Headers) > 0
+}
+
+func validateResponseHeaders(headers openapi3.Headers, header http.Header) (op []ValidationError) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h := headers[name]
+		if h == nil || h.Value == nil {
+			continue
+		}
+		values, ok := header[http.CanonicalHeaderKey(name)]
+		if !ok || len(values) == 0 {
+			if h.Value.Required {
+				op = append(op, ValidationError{In: openapi3.ParameterInHeader, Name: name, Detail: "required header is missing"})
+			}
+			continue
+		}
+		if h.Value.Schema == nil || h.Value.Schema.Value == nil {
+			continue
+		}
+		value, err := parsePrimitive(values[0], h.Value.Schema.Value)
+		if err == nil {
+			err = h.Value.Schema.Value.VisitJSON(value, openapi3.MultiErrors())
+		}
+		if err != nil {
+			op = append(op, validationErrors(err, openapi3.ParameterInHeader, name)...)
+		}
+	}
+	return op
+}
+
+// parsePrimitive converts the string value of a parameter or header into the type of the schema.
+func parsePrimitive(value string, schema *openapi3.Schema) (any, error) {
+	switch {
+	case schema.Type.Is(openapi3.TypeInteger):
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not an integer", value)
+		}
+		return float64(v), nil
+	case schema.Type.Is(openapi3.TypeNumber):
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not a number", value)
+		}
+		return v, nil
+	case schema.Type.Is(openapi3.TypeBoolean):
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not a boolean", value)
+		}
+		return v, nil
+	}
+	return value, nil
+}
+
+func validateResponseBody(content openapi3.Content, header http.Header, body []byte) (op []ValidationError) {
+	if len(content) == 0 {
+		return nil
+	}
+	contentType := header.Get("Content-Type")
+	mediaType := content.Get(contentType)
+	if mediaType == nil {
+		return []ValidationError{{In: "body", Detail: fmt.Sprintf("undocumented content type %q", contentType)}}
+	}
+	if mediaType.Schema == nil || mediaType.Schema.Value == nil || !isJSONContentType(contentType) {
+		return nil
+	}
+	var value any
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&value); err != nil {
+		return []ValidationError{{In: "body", Detail: fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+	if err := mediaType.Schema.Value.VisitJSON(value, openapi3.MultiErrors(), openapi3.VisitAsResponse()); err != nil {
+		return validationErrors(err, "body", "")
+	}
+	return nil
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// isStreamContentType returns true for the media types of streams, text/event-stream and
+// application/x-ndjson, which can be written for as long as the client is connected.
+func isStreamContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == ContentTypeEventStream || mediaType == ContentTypeNDJSON
+}
+
+// Middleware records each response of the next handler, and checks it once the handler
+// has returned. Responses are passed through to the client unchanged. The bodies of streams,
+// e.g. text/event-stream, aren't recorded, so only their status and headers are checked.
+func (v *ResponseValidator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if violation := v.Validate(r, rec.status, w.Header(), rec.body.Bytes()); violation != nil {
+			v.onViolation(r, violation)
+		}
+	})
+}
+
+// responseRecorder copies the status and body of a response as it's written.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	// stream is set if the response is a stream, whose body isn't copied.
+	stream bool
+	body   bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	if !rr.wroteHeader {
+		rr.status = status
+		rr.wroteHeader = true
+		rr.stream = isStreamContentType(rr.Header().Get("Content-Type"))
+	}
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(p []byte) (int, error) {
+	if !rr.wroteHeader {
+		rr.WriteHeader(http.StatusOK)
+	}
+	if !rr.stream {
+		rr.body.Write(p)
+	}
+	return rr.ResponseWriter.Write(p)
+}
+
+func (rr *responseRecorder) Flush() {
+	if f, ok := rr.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (rr *responseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
@@ -0,0 +1,149 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type ResponseTopic struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func newResponseValidatedAPI() *API {
+	api := NewAPI("validate-response")
+	api.StripPkgPaths = []string{"github.com/ihezebin/openapi"}
+	api.Get("/topic").
+		HasResponseModel(http.StatusOK, ModelOf[ResponseTopic]()).
+		HasResponseHeader(http.StatusOK, "X-Total", HeaderParam{
+			Required: true,
+			Type:     PrimitiveTypeInteger,
+		})
+	return api
+}
+
+func TestResponseValidator(t *testing.T) {
+	tests := []struct {
+		name                 string
+		status               int
+		header               map[string]string
+		body                 string
+		expectedUndocumented bool
+		expectedErrors       []string
+	}{
+		{
+			name:   "valid response",
+			status: http.StatusOK,
+			header: map[string]string{"X-Total": "1"},
+			body:   `{"name": "a", "tags": ["b"]}`,
+		},
+		{
+			name:                 "undocumented status",
+			status:               http.StatusNotFound,
+			expectedUndocumented: true,
+		},
+		{
+			name:   "invalid header and body",
+			status: http.StatusOK,
+			header: map[string]string{"X-Total": "abc"},
+			body:   `{"name": "a", "tags": ["b", 1]}`,
+			expectedErrors: []string{
+				"header X-Total: value \"abc\" is not an integer",
+				"body /tags/1: value must be a string",
+			},
+		},
+		{
+			name:   "missing header and field",
+			status: http.StatusOK,
+			body:   `{"tags": null}`,
+			expectedErrors: []string{
+				"header X-Total: required header is missing",
+				"body /name: property \"name\" is missing",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var violations []*ResponseViolation
+			v, err := NewResponseValidator(newResponseValidatedAPI(), WithResponseViolationHandler(func(r *http.Request, v *ResponseViolation) {
+				violations = append(violations, v)
+			}))
+			if err != nil {
+				t.Fatalf("failed to create validator: %v", err)
+			}
+			h := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				for k, v := range test.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			}))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/topic", nil))
+			if w.Body.String() != test.body {
+				t.Errorf("expected the body to be passed through, got %q", w.Body.String())
+			}
+
+			if !test.expectedUndocumented && len(test.expectedErrors) == 0 {
+				if len(violations) > 0 {
+					t.Fatalf("unexpected violations: %v", violations)
+				}
+				return
+			}
+			if len(violations) != 1 {
+				t.Fatalf("expected 1 violation, got %d", len(violations))
+			}
+			if violations[0].Undocumented != test.expectedUndocumented {
+				t.Errorf("expected undocumented %v, got %v", test.expectedUndocumented, violations[0].Undocumented)
+			}
+			actual := violations[0].String()
+			for _, expected := range test.expectedErrors {
+				if !strings.Contains(actual, "GET /topic 200 "+expected) {
+					t.Errorf("expected %q in:\n%s", expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestResponseValidatorStreams(t *testing.T) {
+	api := NewAPI("validate-response")
+	api.Get("/events").HasEventStream(http.StatusOK, EventOf[ResponseTopic]("topic"))
+	var violations []*ResponseViolation
+	v, err := NewResponseValidator(api, WithResponseViolationHandler(func(r *http.Request, v *ResponseViolation) {
+		violations = append(violations, v)
+	}))
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+	var recorded int
+	h := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentTypeEventStream)
+		for i := 0; i < 3; i++ {
+			w.Write([]byte("event: topic\ndata: {\"name\":\"a\",\"tags\":[]}\n\n"))
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		}
+		recorded = w.(*responseRecorder).body.Len()
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events", nil))
+
+	if !w.Flushed {
+		t.Error("expected the stream to be flushed")
+	}
+	if strings.Count(w.Body.String(), "event: topic") != 3 {
+		t.Errorf("expected the events to be passed through, got %q", w.Body.String())
+	}
+	if recorded != 0 {
+		t.Errorf("expected the body of the stream not to be recorded, got %d bytes", recorded)
+	}
+	if len(violations) > 0 {
+		t.Errorf("unexpected violations: %v", violations)
+	}
+}
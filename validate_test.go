@@ -0,0 +1,178 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type CreateTopicRequest struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func newValidatedAPI() *API {
+	api := NewAPI("validate")
+	api.StripPkgPaths = []string{"github.com/ihezebin/openapi"}
+	api.Post("/topic/{id}").
+		HasPathParameter("id", PathParam{
+			Regexp: `^\d+$`,
+		}).
+		HasQueryParameter("limit", QueryParam{
+			Required: true,
+			Type:     PrimitiveTypeInteger,
+		}).
+		HasHeaderParameter("X-Request-ID", HeaderParam{
+			Required: true,
+		}).
+		HasRequestModel(ModelOf[CreateTopicRequest]()).
+		HasResponseModel(http.StatusOK, ModelOf[CreateTopicRequest]())
+	return api
+}
+
+func TestRequestValidator(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		header         map[string]string
+		body           string
+		expectedStatus int
+		expectedErrors []ValidationError
+	}{
+		{
+			name:           "valid request",
+			method:         http.MethodPost,
+			url:            "/topic/123?limit=10",
+			header:         map[string]string{"X-Request-ID": "abc"},
+			body:           `{"name": "a", "tags": ["b"]}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown route",
+			method:         http.MethodGet,
+			url:            "/unknown",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "method not allowed",
+			method:         http.MethodGet,
+			url:            "/topic/123",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "invalid params",
+			method:         http.MethodPost,
+			url:            "/topic/abc?limit=ten",
+			body:           `{"name": "a", "tags": ["b"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []ValidationError{
+				{In: "query", Name: "limit"},
+				{In: "path", Name: "id"},
+				{In: "header", Name: "X-Request-ID"},
+			},
+		},
+		{
+			name:           "invalid body",
+			method:         http.MethodPost,
+			url:            "/topic/123?limit=10",
+			header:         map[string]string{"X-Request-ID": "abc"},
+			body:           `{"name": "a", "tags": [1]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []ValidationError{
+				{In: "body", Pointer: "/tags/0"},
+			},
+		},
+	}
+
+	v, err := NewRequestValidator(newValidatedAPI())
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+	h := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req CreateTopicRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("expected body to be readable by the handler: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(test.method, test.url, strings.NewReader(test.body))
+			r.Header.Set("Content-Type", "application/json")
+			for k, v := range test.header {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != test.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", test.expectedStatus, w.Code, w.Body.String())
+			}
+			if test.expectedStatus == http.StatusOK {
+				return
+			}
+			if ct := w.Header().Get("Content-Type"); ct != ContentTypeProblemJSON {
+				t.Errorf("expected problem content type, got %q", ct)
+			}
+			var p struct {
+				Status int               `json:"status"`
+				Errors []ValidationError `json:"errors"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+				t.Fatalf("failed to decode problem: %v", err)
+			}
+			if p.Status != test.expectedStatus {
+				t.Errorf("expected problem status %d, got %d", test.expectedStatus, p.Status)
+			}
+			for _, expected := range test.expectedErrors {
+				var found bool
+				for _, actual := range p.Errors {
+					if actual.In == expected.In && actual.Name == expected.Name && actual.Pointer == expected.Pointer {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("expected error %v in %v", expected, p.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestRequestValidatorErrorHandler(t *testing.T) {
+	v, err := NewRequestValidator(newValidatedAPI(), WithRequestErrorHandler(func(w http.ResponseWriter, r *http.Request, p *ProblemDetails) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+	w := httptest.NewRecorder()
+	v.Middleware(http.NotFoundHandler()).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	if w.Code != http.StatusTeapot {
+		t.Errorf("expected custom error handler to be used, got status %d", w.Code)
+	}
+}
+
+func TestProblemDetailsJSON(t *testing.T) {
+	p := NewProblemDetails(http.StatusBadRequest, "bad")
+	p.Extensions = map[string]any{"traceId": "123"}
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	expected := `{"detail":"bad","status":400,"title":"Bad Request","traceId":"123"}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+	var actual ProblemDetails
+	if err = json.Unmarshal(data, &actual); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if actual.Status != http.StatusBadRequest || actual.Extensions["traceId"] != "123" {
+		t.Errorf("unexpected round trip result: %+v", actual)
+	}
+}
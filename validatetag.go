@@ -0,0 +1,132 @@
+package openapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// applyValidateTag adds the constraints of a validate struct tag, as used by
+// github.com/go-playground/validator, to the schema, e.g. `validate:"min=1,max=100"`.
+//
+// The min, max, len, gt, gte, lt and lte rules limit the value of numbers, the length of strings,
+// the number of items of arrays, and the number of properties of objects. The oneof rule is an
+// enum, and the email, uri, url and uuid rules set the format of strings. Other rules, and the
+// rules of the elements of slices after dive, aren't documented.
+func applyValidateTag(s *openapi3.Schema, tag string) error {
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			return nil
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				v, err := parseValidateParam(s, value)
+				if err != nil {
+					return fmt.Errorf("validate tag %q: %w", tag, err)
+				}
+				s.Enum = append(s.Enum, v)
+			}
+		case "email":
+			s.Format = "email"
+		case "uri", "url":
+			s.Format = "uri"
+		case "uuid", "uuid4":
+			s.Format = "uuid"
+		case "min", "max", "len", "gt", "gte", "lt", "lte":
+			if err := applyValidateLimit(s, name, param); err != nil {
+				return fmt.Errorf("validate tag %q: %w", tag, err)
+			}
+		}
+	}
+	return nil
+}
+
+func applyValidateLimit(s *openapi3.Schema, rule, param string) error {
+	if s.Type.Is(openapi3.TypeNumber) || s.Type.Is(openapi3.TypeInteger) {
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return fmt.Errorf("%s=%s: %q is not a number", rule, param, param)
+		}
+		switch rule {
+		case "min", "gte":
+			s.Min = &n
+		case "gt":
+			s.Min, s.ExclusiveMin = &n, true
+		case "max", "lte":
+			s.Max = &n
+		case "lt":
+			s.Max, s.ExclusiveMax = &n, true
+		case "len":
+			s.Min, s.Max = &n, &n
+		}
+		return nil
+	}
+	n, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%s=%s: %q is not a length", rule, param, param)
+	}
+	var min, max *uint64
+	switch rule {
+	case "min", "gte":
+		min = &n
+	case "gt":
+		n++
+		min = &n
+	case "max", "lte":
+		max = &n
+	case "lt":
+		if n == 0 {
+			return fmt.Errorf("%s=%s: the length can't be less than zero", rule, param)
+		}
+		n--
+		max = &n
+	case "len":
+		min, max = &n, &n
+	}
+	switch {
+	case s.Type.Is(openapi3.TypeString):
+		if min != nil {
+			s.MinLength = *min
+		}
+		if max != nil {
+			s.MaxLength = max
+		}
+	case s.Type.Is(openapi3.TypeArray):
+		if min != nil {
+			s.MinItems = *min
+		}
+		if max != nil {
+			s.MaxItems = max
+		}
+	case s.Type.Is(openapi3.TypeObject):
+		if min != nil {
+			s.MinProps = *min
+		}
+		if max != nil {
+			s.MaxProps = max
+		}
+	}
+	return nil
+}
+
+// parseValidateParam parses a value of a oneof rule as the type of the schema.
+func parseValidateParam(s *openapi3.Schema, value string) (any, error) {
+	switch {
+	case s.Type.Is(openapi3.TypeInteger):
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("oneof: %q is not an integer", value)
+		}
+		return n, nil
+	case s.Type.Is(openapi3.TypeNumber):
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("oneof: %q is not a number", value)
+		}
+		return n, nil
+	}
+	return value, nil
+}
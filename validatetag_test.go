@@ -0,0 +1,74 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestApplyValidateTag(t *testing.T) {
+	float64Ptr := func(v float64) *float64 { return &v }
+	uint64Ptr := func(v uint64) *uint64 { return &v }
+	tests := []struct {
+		name          string
+		schema        *openapi3.Schema
+		tag           string
+		expected      *openapi3.Schema
+		expectedError string
+	}{
+		{
+			name:     "numbers are limited by value",
+			schema:   openapi3.NewIntegerSchema(),
+			tag:      "required,gt=0,lte=100",
+			expected: &openapi3.Schema{Type: &openapi3.Types{"integer"}, Min: float64Ptr(0), ExclusiveMin: true, Max: float64Ptr(100)},
+		},
+		{
+			name:     "strings are limited by length",
+			schema:   openapi3.NewStringSchema(),
+			tag:      "min=1,lt=65",
+			expected: &openapi3.Schema{Type: &openapi3.Types{"string"}, MinLength: 1, MaxLength: uint64Ptr(64)},
+		},
+		{
+			name:     "arrays are limited by their number of items",
+			schema:   openapi3.NewArraySchema(),
+			tag:      "len=2,dive,min=5",
+			expected: &openapi3.Schema{Type: &openapi3.Types{"array"}, MinItems: 2, MaxItems: uint64Ptr(2)},
+		},
+		{
+			name:     "oneof is an enum of the type of the schema",
+			schema:   openapi3.NewIntegerSchema(),
+			tag:      "oneof=1 2 3",
+			expected: &openapi3.Schema{Type: &openapi3.Types{"integer"}, Enum: []any{int64(1), int64(2), int64(3)}},
+		},
+		{
+			name:     "formats",
+			schema:   openapi3.NewStringSchema(),
+			tag:      "omitempty,email",
+			expected: &openapi3.Schema{Type: &openapi3.Types{"string"}, Format: "email"},
+		},
+		{
+			name:          "invalid limits are reported",
+			schema:        openapi3.NewStringSchema(),
+			tag:           "max=ten",
+			expectedError: `validate tag "max=ten": max=ten: "ten" is not a length`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := applyValidateTag(test.schema, test.tag)
+			if test.expectedError != "" {
+				if err == nil || err.Error() != test.expectedError {
+					t.Fatalf("expected error %q, got %v", test.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.expected, test.schema); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
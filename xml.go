@@ -0,0 +1,150 @@
+package openapi
+
+import (
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var xmlNameType = reflect.TypeFor[xml.Name]()
+
+// isXMLMediaType returns true for XML media types, e.g. application/xml, text/xml and application/atom+xml.
+func isXMLMediaType(mediaType string) bool {
+	mt, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		mt = mediaType
+	}
+	return mt == "application/xml" || mt == "text/xml" || strings.HasSuffix(mt, "+xml")
+}
+
+// registerXMLModel registers the schema of the model as it's encoded by encoding/xml, using the
+// xml struct tags of its fields. Structs are registered with an XML suffix, e.g. UserXML, since
+// their schema differs from the JSON schema of the type. Types that are encoded the same way as
+// JSON, such as strings and numbers, use the JSON schema.
+//
+// The element name of a struct is only documented when its XMLName field is tagged, since the
+// name of a nested element comes from the tag of the field that contains it. Character data,
+// inner XML, comments and ",any" fields can't be described by OpenAPI 3.0, so are left out.
+func (api *API) registerXMLModel(model Model, opts ...ModelOpts) (name string, schema *openapi3.Schema, err error) {
+	t := model.Type
+	// Known types, and types that marshal themselves as text, are encoded as they are in JSON.
+	if _, ok := api.KnownType(t); ok || t.Kind() != reflect.Pointer && implements(t, textMarshalerType) {
+		return api.registerModel(model, opts...)
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return api.registerXMLModel(modelFromType(t.Elem()), opts...)
+	case reflect.Map:
+		return "", nil, fmt.Errorf("unsupported type %v: maps can't be encoded as XML", t)
+	case reflect.Slice, reflect.Array:
+		// Byte slices are encoded as text.
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return "", openapi3.NewStringSchema(), nil
+		}
+		elementName, elementSchema, err := api.registerXMLModel(modelFromType(t.Elem()))
+		if err != nil {
+			return "", nil, withFieldPath("[]", err)
+		}
+		schema = openapi3.NewArraySchema()
+		schema.Items = getSchemaReferenceOrValue(elementName, elementSchema)
+		return "ArrayOf" + elementName, schema, nil
+	case reflect.Struct:
+		return api.registerXMLStruct(t)
+	}
+	return api.registerModel(model, opts...)
+}
+
+func (api *API) registerXMLStruct(t reflect.Type) (name string, schema *openapi3.Schema, err error) {
+	name = api.getModelName(t) + "XML"
+	if schema, ok := api.models[name]; ok {
+		return name, schema, nil
+	}
+	schema = openapi3.NewObjectSchema()
+	schema.Properties = make(openapi3.Schemas)
+	// Register the schema before its fields, so that types that contain themselves reference it.
+	api.models[name] = schema
+	defer func() {
+		if err != nil {
+			delete(api.models, name)
+		}
+	}()
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() {
+			continue
+		}
+		// The fields of embedded structs are promoted.
+		if f.Anonymous && (f.Type.Kind() == reflect.Struct || f.Type.Kind() == reflect.Pointer && f.Type.Elem().Kind() == reflect.Struct) {
+			continue
+		}
+		tag := f.Tag.Get("xml")
+		if tag == "-" {
+			continue
+		}
+		elementName, flags, _ := strings.Cut(tag, ",")
+		options := strings.Split(flags, ",")
+		if f.Name == "XMLName" && f.Type == xmlNameType {
+			if elementName != "" {
+				schema.XML = &openapi3.XML{Name: elementName}
+			}
+			continue
+		}
+		if slices.ContainsFunc(options, func(o string) bool {
+			return o == "chardata" || o == "cdata" || o == "innerxml" || o == "comment" || o == "any"
+		}) {
+			continue
+		}
+		// Slices in a parent element, e.g. items>item, are documented as wrapped arrays.
+		parent, child, nested := cutLast(elementName, ">")
+		if elementName == "" {
+			elementName = f.Name
+		}
+		fieldSchemaName, fieldSchema, err := api.registerXMLModel(modelFromType(f.Type))
+		if err != nil {
+			return name, schema, withFieldPath("."+f.Name, err)
+		}
+		ref := getSchemaReferenceOrValue(fieldSchemaName, fieldSchema)
+		if nested {
+			elementName = child
+			if ref.Value != nil && ref.Value.Type.Is(openapi3.TypeArray) && !strings.Contains(parent, ">") {
+				elementName = parent
+				value := *ref.Value
+				value.XML = &openapi3.XML{Wrapped: true}
+				items := openapi3.NewSchema()
+				items.AllOf = openapi3.SchemaRefs{value.Items}
+				items.XML = &openapi3.XML{Name: child}
+				value.Items = items.NewRef()
+				ref = value.NewRef()
+			}
+		}
+		if ref.Value != nil {
+			value := *ref.Value
+			if len(f.Index) == 1 {
+				if value.Description, value.Deprecated, err = api.getTypeFieldComment(t.PkgPath(), t.Name(), f.Name); err != nil {
+					return name, schema, withFieldPath("."+f.Name, fmt.Errorf("failed to get comments: %w", err))
+				}
+			}
+			if slices.Contains(options, "attr") {
+				value.XML = &openapi3.XML{Attribute: true}
+			}
+			ref = value.NewRef()
+		}
+		schema.Properties[elementName] = ref
+		if isFieldRequired(f.Type.Kind() == reflect.Pointer, slices.Contains(options, "omitempty")) {
+			schema.Required = append(schema.Required, elementName)
+		}
+	}
+	return name, schema, nil
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return "", s, false
+}